@@ -1,145 +1,518 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"os"
-	"path/filepath"
 	"regexp"
+	"slices"
 	"strings"
 
+	"github.com/lpsm-dev/gtoc/internal/config"
+	"github.com/lpsm-dev/gtoc/internal/diff"
+	"github.com/lpsm-dev/gtoc/internal/fsutil"
+	"github.com/lpsm-dev/gtoc/internal/generator"
+	"github.com/lpsm-dev/gtoc/internal/i18n"
+	"github.com/lpsm-dev/gtoc/internal/lint"
 	"github.com/lpsm-dev/gtoc/internal/logger"
 	"github.com/spf13/cobra"
 )
 
-var readmePath string
+var (
+	readmePath     string
+	analyzeFix     bool
+	analyzeDryRun  bool
+	analyzeCheck   bool
+	analyzeRevert  bool
+	backToTopLevel string
+	policyPath     string
+	analyzePreview string
+	listRules      bool
+	reportFormat   string
+)
+
+// errAnalyzeFindings is returned when analyze reports error-level findings.
+var errAnalyzeFindings = errors.New("analyze found problems")
+
+// errAnalyzeChanges is returned by analyze --check when --fix (or --revert)
+// would change the file.
+var errAnalyzeChanges = errors.New("README needs fixes")
+
+// The --preview modes of analyze --dry-run.
+const (
+	previewDiff     = "diff"
+	previewFull     = "full"
+	previewRendered = "rendered"
+)
+
+// diffContext is how many unchanged lines the --dry-run diff shows around
+// each change.
+const diffContext = 3
 
 // Constant markers used to delimit and format the generated README sections.
 const (
 	beginDocsMarker = "<!-- BEGIN_DOCS -->"
 	endDocsMarker   = "<!-- END_DOCS -->"
 	readmeAnchor    = "<a name=\"readme-top\"></a>"
-	backToTopLink   = "<p align=\"right\">(<a href=\"#readme-top\">back to top</a>)</p>"
+	tocEndMarker    = "<!-- END_TABLE_OF_CONTENTS -->"
 )
 
-// h1HeadingPattern matches a top-level (H1) markdown heading line.
-var h1HeadingPattern = regexp.MustCompile(`^#\s+(.+)$`)
+// atxHeadingPattern matches a markdown heading line, capturing its hashes.
+var atxHeadingPattern = regexp.MustCompile(`^(#{1,6})\s+(.+)$`)
 
 // codeFencePattern matches the leading run of backticks or tildes that opens
 // or closes a fenced code block.
 var codeFencePattern = regexp.MustCompile("^(`{3,}|~{3,})")
 
-// analyzeCmd adds best practices formatting to README files.
+// analyzeCmd lints README files and applies best practices formatting.
 var analyzeCmd = &cobra.Command{
 	Use:   "analyze",
-	Short: "Analyze and update README.md with best practices",
-	Long: `Analyze the README.md file and add best practices elements such as:
-- <!-- BEGIN_DOCS --> and <a name="readme-top"></a> in the header
-- <p align="right">(<a href="#readme-top">back to top</a>)</p> at the end of each main heading (#) section`,
+	Short: "Lint a README's structure and apply best practices with --fix",
+	Long: `Check a README against a set of rules and report what breaks them. Each
+rule has an ID and a severity (error, warning or info); the command exits
+with a non-zero status when an error is found. Rules about the README
+layout:
+- docs-markers: <!-- BEGIN_DOCS --> and <a name="readme-top"></a> in the
+  header and <!-- END_DOCS --> at the end
+- language-bar: a language bar linking the document's translations
+  (README.md, README_en.md, README.pt-BR.md, ...), between
+  <!-- START_LANGUAGE_BAR --> markers
+- back-to-top: <p align="right">(<a href="#readme-top">back to top</a>)</p>
+  at the end of each main heading (#) section, or of the sections at the
+  levels set with --back-to-top-level (2 for ##, 1-2 for both)
+
+and about its headings: heading-increment, single-h1,
+duplicate-sibling-heading, empty-section, heading-trailing-punctuation and
+toc-markers. Run with --list-rules to see them all.
+
+A section policy, under "sections" in the config or in a --policy file,
+lists the sections a README must or may have, with aliases, in their
+allowed order. required-sections reports the missing ones and, when the
+policy sets "scaffold", adds them with --fix; section-order reports the
+ones out of order:
+
+  {"sections": {"level": 2, "scaffold": true, "list": [
+    {"name": "Installation", "aliases": ["Install"], "required": true},
+    {"name": "Usage", "required": true},
+    {"name": "FAQ"}]}}
+
+--format prints the findings as text (the default), json, sarif,
+checkstyle or github (workflow commands that annotate the file in GitHub
+Actions), each with its file, line, column, rule ID, message and a
+suggested fix.
+
+--fix applies the fixes of the rules that have one. --dry-run shows what
+--fix would change without writing: a unified diff by default, or with
+--preview full the whole fixed file, or with --preview rendered the fixed
+file rendered for the terminal. --check writes nothing and exits with a
+non-zero status when --fix would change the file. When the fixes add or
+rename headings, --fix regenerates the table of contents the way gtoc
+generate would with the same --depth, --exclude, numbering and other TOC
+flags, so pass the ones the README was generated with; an up-to-date
+table of contents is otherwise left as it is.
+
+--revert removes what --fix adds: the BEGIN_DOCS header and readme-top
+anchor, the END_DOCS marker, the back-to-top links, the language bar and
+the table of contents, leaving the rest of the file unchanged. It goes with
+--dry-run and --check too.
+
+Rule severities are set,
+or rules turned off, in the config file:
+
+  {"rules": {"single-h1": "error", "empty-section": "off"}}
+
+The back-to-top text follows the document's language (see --lang).
+
+Example:
+  gtoc analyze
+  gtoc analyze --fix --file docs/README.md
+  gtoc analyze --fix --back-to-top-level 2
+  gtoc analyze --dry-run
+  gtoc analyze --dry-run --preview rendered
+  gtoc analyze --check
+  gtoc analyze --fix --depth 2 --number-headings
+  gtoc analyze --revert
+  gtoc analyze --format sarif > gtoc.sarif
+  gtoc analyze --format github`,
 	RunE: runAnalyze,
 }
 
-// runAnalyze reads the target README, adds any missing best-practice
-// markers, and writes the updated content back to disk.
+// runAnalyze lints the target README, fixing it first with --fix, and
+// prints the findings. With --revert it removes what --fix adds instead, and
+// with --dry-run or --check it reviews the changes without writing.
 func runAnalyze(cmd *cobra.Command, args []string) error {
+	if listRules {
+		return printRules()
+	}
 	if readmePath == "" {
 		readmePath = "README.md"
 	}
+	format, err := validateAnalyzeFlags()
+	if err != nil {
+		return err
+	}
 
 	logger.Debug("Analyzing README file", "path", readmePath)
 
-	absFilePath, err := filepath.Abs(readmePath)
+	absFilePath, err := validateFileExists(readmePath)
 	if err != nil {
-		return fmt.Errorf("failed to get absolute path: %w", err)
-	}
-
-	if _, err := os.Stat(absFilePath); os.IsNotExist(err) {
-		return fmt.Errorf("file does not exist: %s", absFilePath)
+		return err
 	}
+	return analyzeFile(absFilePath, format)
+}
 
+// analyzeFile reads the README at absFilePath and reverts it with --revert,
+// or else lints it, reporting findings in format.
+func analyzeFile(absFilePath string, format lint.Format) error {
 	logger.Debug("Reading file", "path", absFilePath)
-	content, err := os.ReadFile(absFilePath)
+	snap, err := fsutil.ReadSnapshot(absFilePath)
 	if err != nil {
 		return fmt.Errorf("failed to read file: %w", err)
 	}
-	contentStr := string(content)
+	if analyzeRevert {
+		return revertAnalyzed(absFilePath, snap)
+	}
+	return lintAnalyzed(absFilePath, snap, format)
+}
+
+// lintAnalyzed lints the README read as snap and prints the findings in
+// format, fixing it first with --fix. With --dry-run or --check it reviews
+// the fixes instead, without writing or reporting.
+func lintAnalyzed(absFilePath string, snap fsutil.Snapshot, format lint.Format) error {
+	doc, linter, err := prepareAnalysis(absFilePath, snap.Text)
+	if err != nil {
+		return err
+	}
+
+	if analyzeDryRun || analyzeCheck {
+		return reviewFixes(snap.Text, fixDocument(linter, doc).Content)
+	}
+	if analyzeFix {
+		doc = fixDocument(linter, doc)
+		if err := writeAnalyzed(absFilePath, doc.Content, snap); err != nil {
+			return err
+		}
+	}
+
+	return writeFindings(format, linter, doc)
+}
+
+// writeFindings prints the findings of linter on doc as a report in format,
+// failing when any of them is an error.
+func writeFindings(format lint.Format, linter *lint.Linter, doc *lint.Document) error {
+	findings := linter.Check(doc)
+	if err := lint.WriteReport(os.Stdout, format, readmePath, findings, linter.Rules()); err != nil {
+		return fmt.Errorf("failed to write report: %w", err)
+	}
+	if n := lint.Count(findings, lint.SeverityError); n > 0 {
+		return fmt.Errorf("%w: %d error(s); run gtoc analyze --fix to fix what can be fixed", errAnalyzeFindings, n)
+	}
+	return nil
+}
+
+// fixDocument applies every fix of linter to doc, then, when the fixes
+// added or renamed headings, regenerates its table of contents to list them.
+func fixDocument(linter *lint.Linter, doc *lint.Document) *lint.Document {
+	fixed := linter.Fix(doc)
+	if sameHeadings(doc.Headings, fixed.Headings) {
+		return fixed
+	}
+	return fixed.RefreshTOC()
+}
+
+// sameHeadings reports whether a and b list the same headings, with the
+// same levels and texts, in the same order.
+func sameHeadings(a, b []*generator.Heading) bool {
+	return slices.EqualFunc(a, b, func(x, y *generator.Heading) bool {
+		return x.Level == y.Level && x.Text == y.Text
+	})
+}
 
-	if !strings.Contains(contentStr, beginDocsMarker) {
-		logger.Info("Adding header markers", "marker", beginDocsMarker)
-		contentStr = beginDocsMarker + "\n" + readmeAnchor + "\n\n" + contentStr
+// validateAnalyzeFlags checks that the analyze flags go together and
+// returns the report format.
+func validateAnalyzeFlags() (lint.Format, error) {
+	if analyzeCheck && (analyzeFix || analyzeDryRun) {
+		return "", fmt.Errorf("--check cannot be used with --fix or --dry-run")
+	}
+	if analyzeRevert && analyzeFix {
+		return "", fmt.Errorf("--revert cannot be used with --fix")
+	}
+	switch analyzePreview {
+	case previewDiff, previewFull, previewRendered:
+	default:
+		return "", fmt.Errorf("unknown preview %q (use diff, full or rendered)", analyzePreview)
 	}
+	return lint.ParseFormat(reportFormat)
+}
 
-	logger.Info("Processing headings and adding back-to-top links")
-	contentStr = addBackToTopLinks(contentStr)
+// revertAnalyzed removes the best practices scaffolding from the README
+// read as snap, reviewing the result with --dry-run or --check instead of
+// writing it.
+func revertAnalyzed(absFilePath string, snap fsutil.Snapshot) error {
+	reverted := revertBestPractices(snap.Text)
+	if analyzeDryRun || analyzeCheck {
+		return reviewFixes(snap.Text, reverted)
+	}
+	return writeAnalyzed(absFilePath, reverted, snap)
+}
+
+// analyzeAction returns the flag that makes analyze write its changes.
+func analyzeAction() string {
+	if analyzeRevert {
+		return "--revert"
+	}
+	return "--fix"
+}
+
+// reviewFixes compares the README's content with its fixed content: with
+// --check it fails when they differ, and with --dry-run it previews the
+// fixed file.
+func reviewFixes(content, fixed string) error {
+	if analyzeCheck {
+		if fixed == content {
+			logger.Info("Nothing to change", "path", readmePath)
+			return nil
+		}
+		fmt.Printf("%s: would be changed by gtoc analyze %s\n", readmePath, analyzeAction())
+		return fmt.Errorf("%w; run gtoc analyze %s to apply them", errAnalyzeChanges, analyzeAction())
+	}
+
+	logger.Info("Dry run mode - not updating file")
+	if fixed == content {
+		fmt.Printf("Dry run mode. Nothing to change in %s\n", readmePath)
+		return nil
+	}
+	previewFixes(content, fixed)
+	return nil
+}
+
+// previewFixes prints the fixed file in the --preview mode, falling back to
+// the full file when it cannot be rendered.
+func previewFixes(content, fixed string) {
+	switch analyzePreview {
+	case previewRendered:
+		rendered, err := renderMarkdown(fixed)
+		if err == nil {
+			fmt.Println("Dry run mode. The following is how the fixed file would look:")
+			fmt.Println(rendered)
+			return
+		}
+		logger.Warn("Failed to render content, falling back to plain output", "error", err)
+	case previewDiff:
+		fmt.Printf("Dry run mode. gtoc analyze %s would make these changes:\n", analyzeAction())
+		fmt.Print(diff.Unified(readmePath, readmePath+" (updated)", content, fixed, diffContext))
+		return
+	}
+	fmt.Println("Dry run mode. The document would be updated to:")
+	fmt.Println("\n" + fixed)
+}
+
+// writeAnalyzed writes the fixed content back to the file read as snap,
+// unless nothing changed.
+func writeAnalyzed(absFilePath, content string, snap fsutil.Snapshot) error {
+	if content == snap.Text {
+		logger.Info("Nothing to change", "path", readmePath)
+		return nil
+	}
 
 	logger.Debug("Writing updated content to file", "path", absFilePath)
-	if err := os.WriteFile(absFilePath, []byte(contentStr), 0644); err != nil {
+	opts := writeOptions()
+	opts.Expect = snap.Fingerprint
+	if err := fsutil.WriteText(absFilePath, content, opts); err != nil {
 		return fmt.Errorf("failed to write file: %w", err)
 	}
 
 	logger.Info("File updated successfully", "path", readmePath)
-	fmt.Printf("Successfully updated %s with best practices\n", readmePath)
+	switch {
+	case analyzeRevert:
+		fmt.Printf("Successfully removed the gtoc markers and links from %s\n", readmePath)
+	case lint.Format(strings.ToLower(reportFormat)) == lint.FormatText:
+		fmt.Printf("Successfully updated %s with best practices\n", readmePath)
+	}
 	return nil
 }
 
-// addBackToTopLinks ensures every top-level (H1) section ends with a
-// back-to-top link and that the content is terminated with the END_DOCS
-// marker. Any content that already follows an existing END_DOCS marker is
-// preserved verbatim after the marker instead of being discarded.
-func addBackToTopLinks(content string) string {
-	hasEndDocsMarker := strings.Contains(content, endDocsMarker)
-	logger.Debug("Checking for END_DOCS marker", "present", hasEndDocsMarker)
+// prepareAnalysis returns content, the text of the README at absFilePath,
+// as a lint document together with the linter to run on it: the structure,
+// code fence, link, section and README layout rules, at the severities set
+// in the config.
+func prepareAnalysis(absFilePath, content string) (*lint.Document, *lint.Linter, error) {
+	cfg, err := loadConfig()
+	if err != nil {
+		return nil, nil, err
+	}
+	catalog, fallback, err := messageCatalog()
+	if err != nil {
+		return nil, nil, err
+	}
+	slug, err := slugger()
+	if err != nil {
+		return nil, nil, err
+	}
+	lang := catalog.Resolve(language, fallback, absFilePath, content)
+	logger.Debug("Resolved document language", "path", absFilePath, "lang", lang)
+
+	bar, err := languageBar(catalog, fallback, absFilePath, lang)
+	if err != nil {
+		return nil, nil, err
+	}
+	messages := catalog.Messages(lang)
+	levels, err := parseLevelRange(backToTopLevel)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid --back-to-top-level: %w", err)
+	}
+	policy, err := sectionPolicy(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	linter, err := lint.New(analysisRules(policy, i18n.BackToTopLink(messages.BackToTop), bar, levels), cfg.Rules)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid rules in config: %w", err)
+	}
 
+	opts, err := tocOptions()
+	if err != nil {
+		return nil, nil, err
+	}
+	toc := newTOCGenerator(absFilePath, opts, messages)
+	update := func(content string) string { return generatedContent(toc, content) }
+	gen := generator.NewGenerator(absFilePath, 0, nil, generator.WithMessages(messages), generator.WithSlugger(slug))
+	return lint.NewDocument(absFilePath, content, gen, lint.WithTOC(toc, update)), linter, nil
+}
+
+// analysisRules returns every rule analyze runs, in order: the structure,
+// code fence, link and section rules, then the README layout rules for
+// link, bar and levels (see readmeRules). --list-rules shares it so the
+// list cannot drift from the rules that run.
+func analysisRules(policy *config.SectionPolicy, link, bar string, levels levelRange) []lint.Rule {
+	rules := append(lint.StructureRules(), lint.FenceRules()...)
+	rules = append(rules, lint.LinkRules()...)
+	rules = append(rules, sectionRules(policy)...)
+	return append(rules, readmeRules(link, bar, levels)...)
+}
+
+// sectionPolicy returns the section policy read from --policy, or else the
+// one in the config, or nil when there is none.
+func sectionPolicy(cfg config.Config) (*config.SectionPolicy, error) {
+	if policyPath == "" {
+		return cfg.Sections, nil
+	}
+	policy, err := config.LoadPolicy(policyPath)
+	if err != nil {
+		return nil, err
+	}
+	return &policy, nil
+}
+
+// printRules lists every analyze rule with its configured severity.
+func printRules() error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	policy, err := sectionPolicy(cfg)
+	if err != nil {
+		return err
+	}
+	linter, err := lint.New(analysisRules(policy, "", "", levelRange{}), cfg.Rules)
+	if err != nil {
+		return fmt.Errorf("invalid rules in config: %w", err)
+	}
+
+	for _, r := range linter.Rules() {
+		fix := ""
+		if r.Fix != nil {
+			fix = " (fixable)"
+		}
+		fmt.Printf("%-30s %-8s %s%s\n", r.ID, r.Severity, r.Description, fix)
+	}
+	return nil
+}
+
+// frontMatterEnd returns the offset just past a YAML front matter block at
+// the start of content, or 0 when there is none. The header is inserted
+// after it so the front matter stays first in the file.
+func frontMatterEnd(content string) int {
+	if !strings.HasPrefix(content, "---\n") {
+		return 0
+	}
+	end := strings.Index(content[4:], "\n---\n")
+	if end < 0 {
+		return 0
+	}
+	return 4 + end + len("\n---\n")
+}
+
+// addBackToTopLinks ensures every section before the END_DOCS marker whose
+// heading level is in levels ends with link, a back-to-top link. Content
+// from the marker on is preserved verbatim.
+func addBackToTopLinks(content, link string, levels levelRange) string {
 	body, trailing := splitAtEndDocsMarker(content)
 
-	starts := findH1LineStarts(body)
+	starts := findHeadingLineStarts(body, levels.max)
 	logger.Debug("Found headings", "count", len(starts))
-
 	if len(starts) == 0 {
-		if hasEndDocsMarker {
-			// Nothing to add; the file is unchanged.
-			return content
-		}
-		logger.Info("No headings found, adding END_DOCS marker")
-		return body + "\n" + endDocsMarker + "\n"
+		return content
 	}
 
 	var sb strings.Builder
-	sb.WriteString(body[:starts[0]])
-
+	sb.WriteString(body[:starts[0].offset])
 	for i, start := range starts {
-		end := len(body)
-		if i < len(starts)-1 {
-			end = starts[i+1]
+		section := body[start.offset:sectionEnd(body, starts, i)]
+		if start.level >= levels.min {
+			section = processSection(section, link)
 		}
-		sb.WriteString(processSection(body[start:end]))
+		sb.WriteString(section)
 	}
-
-	logger.Debug("Adding END_DOCS marker")
-	sb.WriteString(endDocsMarker)
 	sb.WriteString(trailing)
 	return sb.String()
 }
 
-// splitAtEndDocsMarker splits content around an existing END_DOCS marker. It
-// returns the body preceding the marker and the trailing content that must
-// be preserved verbatim after it. When no marker is present, the whole
-// content is treated as the body and a leading newline is returned as the
-// separator for the marker that will be appended.
-func splitAtEndDocsMarker(content string) (body, trailing string) {
-	if !strings.Contains(content, endDocsMarker) {
-		return content, "\n"
+// sectionEnd returns the offset where the section starting at starts[i]
+// ends: the start of the next one, or the end of body.
+func sectionEnd(body string, starts []headingStart, i int) int {
+	if i < len(starts)-1 {
+		return starts[i+1].offset
+	}
+	return len(body)
+}
+
+// addEndDocsMarker appends the END_DOCS marker to content unless it already
+// has one.
+func addEndDocsMarker(content string) string {
+	if strings.Contains(content, endDocsMarker) {
+		return content
 	}
+	if !strings.HasSuffix(content, "\n") {
+		content += "\n"
+	}
+	return content + endDocsMarker + "\n"
+}
 
+// splitAtEndDocsMarker splits content at its last END_DOCS marker. It
+// returns the body preceding the marker and the rest, starting with the
+// marker, which must be preserved verbatim. When no marker is present, the
+// whole content is the body.
+func splitAtEndDocsMarker(content string) (body, trailing string) {
 	endPos := strings.LastIndex(content, endDocsMarker)
-	return content[:endPos], content[endPos+len(endDocsMarker):]
+	if endPos < 0 {
+		return content, ""
+	}
+	return content[:endPos], content[endPos:]
 }
 
-// findH1LineStarts returns the byte offsets, within body, of every top-level
-// (H1) heading line. Lines inside fenced code blocks (``` or ~~~) are
+// headingStart is where a heading line starts within a document body.
+type headingStart struct {
+	offset int
+	level  int
+}
+
+// findHeadingLineStarts returns the start of every heading line in body at
+// maxLevel or shallower. Lines inside fenced code blocks (``` or ~~~) are
 // skipped so a literal "# " inside a code sample is never treated as a
 // heading.
-func findH1LineStarts(body string) []int {
-	var starts []int
+func findHeadingLineStarts(body string, maxLevel int) []headingStart {
+	var starts []headingStart
 	inFence := false
 	fenceMarker := ""
 	offset := 0
@@ -147,8 +520,8 @@ func findH1LineStarts(body string) []int {
 	for _, line := range strings.Split(body, "\n") {
 		if marker := codeFenceRune(line); marker != "" {
 			inFence, fenceMarker = toggleFence(inFence, fenceMarker, marker)
-		} else if !inFence && h1HeadingPattern.MatchString(line) {
-			starts = append(starts, offset)
+		} else if m := atxHeadingPattern.FindStringSubmatch(line); !inFence && m != nil && len(m[1]) <= maxLevel {
+			starts = append(starts, headingStart{offset: offset, level: len(m[1])})
 		}
 
 		offset += len(line) + 1
@@ -181,12 +554,19 @@ func toggleFence(inFence bool, currentMarker, marker string) (bool, string) {
 	}
 }
 
-// processSection appends a back-to-top link to a single H1 section (from its
-// heading line up to, but not including, the next H1 heading) unless the
-// section already contains one.
-func processSection(section string) string {
-	if strings.Contains(section, backToTopLink) {
-		return section
+// processSection appends link to section, the text from a heading line up
+// to, but not including, the next heading no deeper than the deepest
+// --back-to-top-level, so it holds any subsections below that level. A
+// back-to-top link the section already contains, in any language, is
+// replaced with link instead; the one closing a table of contents in the
+// section does not count.
+func processSection(section, link string) string {
+	from := 0
+	if at := strings.LastIndex(section, tocEndMarker); at >= 0 {
+		from = at + len(tocEndMarker)
+	}
+	if loc := i18n.FindBackToTopLink(section[from:]); loc != nil {
+		return section[:from+loc[0]] + link + section[from+loc[1]:]
 	}
 
 	if !strings.HasSuffix(section, "\n") {
@@ -196,9 +576,22 @@ func processSection(section string) string {
 		section += "\n"
 	}
 
-	return section + backToTopLink + "\n\n"
+	return section + link + "\n\n"
 }
 
 func init() {
 	analyzeCmd.Flags().StringVar(&readmePath, "file", "README.md", "Path to the README.md file to analyze")
+	analyzeCmd.Flags().BoolVar(&analyzeFix, "fix", false, "Apply the fixes of the rules that have one and write the file")
+	analyzeCmd.Flags().BoolVar(&analyzeDryRun, "dry-run", false, "Preview what --fix would change without writing")
+	analyzeCmd.Flags().StringVar(&analyzePreview, "preview", previewDiff, "What --dry-run shows: diff, full (the fixed file) or rendered (the fixed file rendered)")
+	analyzeCmd.Flags().BoolVar(&analyzeCheck, "check", false, "Exit with a non-zero status if --fix (or --revert) would change the file, without writing")
+	analyzeCmd.Flags().BoolVar(&analyzeRevert, "revert", false, "Remove the markers, links, language bar and table of contents that --fix adds")
+	analyzeCmd.Flags().StringVar(&backToTopLevel, "back-to-top-level", "1", "Heading level, or range of levels such as 1-2, whose sections end with a back-to-top link")
+	analyzeCmd.Flags().StringVar(&policyPath, "policy", "", "Section policy file listing the required and optional sections, overriding the config's \"sections\"")
+	analyzeCmd.Flags().BoolVar(&listRules, "list-rules", false, "List the rules with their severity and exit")
+	analyzeCmd.Flags().StringVar(&reportFormat, "format", string(lint.FormatText), "Output format of the findings: text, json, sarif, checkstyle or github")
+	addTOCFlags(analyzeCmd)
+	addLangFlag(analyzeCmd)
+	addSlugFlag(analyzeCmd)
+	addWriteFlags(analyzeCmd)
 }
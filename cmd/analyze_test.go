@@ -1,11 +1,14 @@
 package cmd
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
 	"regexp"
 	"strings"
 	"testing"
+
+	"github.com/lpsm-dev/gtoc/internal/i18n"
 )
 
 // analyzeTestCase describes one analyze command scenario: the README
@@ -100,6 +103,36 @@ func analyzeTestCases() []analyzeTestCase {
 	}
 }
 
+// setupAnalyzeTest resets the root command and every analyze flag variable,
+// since analyze's own flags are not re-registered by resetRootCmd.
+func setupAnalyzeTest() {
+	resetRootCmd()
+	resetTOCFlags()
+	readmePath = ""
+	analyzeFix = false
+	analyzeDryRun = false
+	analyzeCheck = false
+	analyzeRevert = false
+	backToTopLevel = "1"
+	policyPath = ""
+	analyzePreview = "diff"
+	listRules = false
+	reportFormat = "text"
+	language = ""
+	slugStyle = "github"
+}
+
+// layoutOnlyConfig writes a config that turns off the toc-markers rule, so
+// --fix only applies the README layout rules, and returns its path.
+func layoutOnlyConfig(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "gtoc.json")
+	if err := os.WriteFile(path, []byte(`{"rules": {"toc-markers": "off"}}`), 0644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	return path
+}
+
 func TestAnalyzeCommand(t *testing.T) {
 	tempDir := t.TempDir()
 
@@ -113,6 +146,33 @@ func TestAnalyzeCommand(t *testing.T) {
 // runAnalyzeTestCase writes tt's initial content to a scratch file, runs the
 // analyze command against it, and checks the result against tt's
 // expectations.
+// writeTestFiles creates each file of files, keyed by path, with its
+// content.
+func writeTestFiles(t *testing.T, files map[string]string) {
+	t.Helper()
+	for path, content := range files {
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to create test file: %v", err)
+		}
+	}
+}
+
+// runAnalyzeFix runs analyze --fix on path with the extra args and returns the
+// updated content.
+func runAnalyzeFix(t *testing.T, path string, args ...string) string {
+	t.Helper()
+	setupAnalyzeTest()
+	RootCmd.SetArgs(append([]string{"analyze", "--fix", "--file", path}, args...))
+	if err := RootCmd.Execute(); err != nil {
+		t.Fatalf("analyze command failed: %v", err)
+	}
+	updated, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read updated file: %v", err)
+	}
+	return string(updated)
+}
+
 func runAnalyzeTestCase(t *testing.T, tempDir string, tt analyzeTestCase) {
 	t.Helper()
 
@@ -121,9 +181,8 @@ func runAnalyzeTestCase(t *testing.T, tempDir string, tt analyzeTestCase) {
 		t.Fatalf("failed to create test file: %v", err)
 	}
 
-	resetRootCmd()
-	readmePath = ""
-	RootCmd.SetArgs([]string{"analyze", "--file", testFile})
+	setupAnalyzeTest()
+	RootCmd.SetArgs([]string{"analyze", "--fix", "--config", layoutOnlyConfig(t), "--file", testFile})
 
 	if err := RootCmd.Execute(); err != nil {
 		t.Fatalf("analyze command failed: %v", err)
@@ -160,12 +219,323 @@ func TestAnalyzeCommandErrors(t *testing.T) {
 	t.Run("Non-existent file", func(t *testing.T) {
 		nonExistentFile := filepath.Join(tempDir, "non_existent.md")
 
-		resetRootCmd()
-		readmePath = ""
-		RootCmd.SetArgs([]string{"analyze", "--file", nonExistentFile})
+		setupAnalyzeTest()
+		RootCmd.SetArgs([]string{"analyze", "--fix", "--file", nonExistentFile})
 
 		if err := RootCmd.Execute(); err == nil {
 			t.Error("expected error for non-existent file, got nil")
 		}
 	})
 }
+
+func TestAnalyzeCommandPreservesPermissions(t *testing.T) {
+	testFile := filepath.Join(t.TempDir(), "README.md")
+	if err := os.WriteFile(testFile, []byte("# Heading 1\nContent\n"), 0600); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+
+	setupAnalyzeTest()
+	RootCmd.SetArgs([]string{"analyze", "--fix", "--file", testFile})
+	if err := RootCmd.Execute(); err != nil {
+		t.Fatalf("analyze command failed: %v", err)
+	}
+
+	info, err := os.Stat(testFile)
+	if err != nil {
+		t.Fatalf("failed to stat file: %v", err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("analyze should preserve the file mode, got %v", info.Mode().Perm())
+	}
+}
+
+func TestAnalyzeCommandLocalizesBackToTop(t *testing.T) {
+	testFile := filepath.Join(t.TempDir(), "README.md")
+	initial := "# Heading 1\nContent\n\n<p align=\"right\">(<a href=\"#readme-top\">back to top</a>)</p>\n\n# Heading 2\nMore\n"
+	if err := os.WriteFile(testFile, []byte(initial), 0644); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+
+	setupAnalyzeTest()
+	RootCmd.SetArgs([]string{"analyze", "--fix", "--config", layoutOnlyConfig(t), "--lang", "pt-BR", "--file", testFile})
+	if err := RootCmd.Execute(); err != nil {
+		t.Fatalf("analyze command failed: %v", err)
+	}
+	language = ""
+
+	updated, err := os.ReadFile(testFile)
+	if err != nil {
+		t.Fatalf("failed to read updated file: %v", err)
+	}
+	content := string(updated)
+	if strings.Contains(content, ">back to top<") {
+		t.Errorf("existing back-to-top links should be translated, got:\n%s", content)
+	}
+	if got := strings.Count(content, ">voltar ao topo<"); got != 2 {
+		t.Errorf("expected 2 localized back-to-top links, got %d in:\n%s", got, content)
+	}
+}
+
+func TestAnalyzeCommandLanguageBar(t *testing.T) {
+	tempDir := t.TempDir()
+	portuguese := filepath.Join(tempDir, "README.md")
+	english := filepath.Join(tempDir, "README_en.md")
+	configFile := filepath.Join(tempDir, "gtoc.json")
+	writeTestFiles(t, map[string]string{
+		portuguese: "# Visão Geral\nTexto\n",
+		english:    "---\ntitle: Demo\n---\n# Overview\nText\n",
+		configFile: `{"lang": "pt-BR"}`,
+	})
+	analyze := func(path string) string {
+		t.Helper()
+		return runAnalyzeFix(t, path, "--config", configFile)
+	}
+
+	bar := languageBarStart + "\n[Português](README.md) | [English](README_en.md)\n" + languageBarEnd
+	first := analyze(portuguese)
+	if !strings.Contains(first, bar) {
+		t.Errorf("README.md should contain the language bar %q, got:\n%s", bar, first)
+	}
+	if second := analyze(portuguese); second != first {
+		t.Errorf("refreshing the language bar should be idempotent:\nfirst:\n%s\nsecond:\n%s", first, second)
+	}
+
+	translated := analyze(english)
+	if !strings.HasPrefix(translated, "---\ntitle: Demo\n---\n"+beginDocsMarker) {
+		t.Errorf("the header should be inserted after the front matter, got:\n%s", translated)
+	}
+	if !strings.Contains(translated, bar) {
+		t.Errorf("README_en.md should contain the same language bar, got:\n%s", translated)
+	}
+
+	if err := os.Remove(english); err != nil {
+		t.Fatalf("failed to remove translation: %v", err)
+	}
+	if alone := analyze(portuguese); strings.Contains(alone, languageBarStart) {
+		t.Errorf("the language bar should be removed once no translation is left, got:\n%s", alone)
+	}
+}
+
+func TestAnalyzeCommandReportsWithoutFix(t *testing.T) {
+	tempDir := t.TempDir()
+	testFile := filepath.Join(tempDir, "README.md")
+	initial := "# Title\n\n### Skipped\n\ntext\n"
+	if err := os.WriteFile(testFile, []byte(initial), 0644); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+
+	setupAnalyzeTest()
+	RootCmd.SetArgs([]string{"analyze", "--file", testFile})
+	if err := RootCmd.Execute(); !errors.Is(err, errAnalyzeFindings) {
+		t.Errorf("missing README markers should fail analyze, got %v", err)
+	}
+	if updated, _ := os.ReadFile(testFile); string(updated) != initial {
+		t.Errorf("analyze without --fix must not write, got:\n%s", updated)
+	}
+
+	configFile := filepath.Join(tempDir, "gtoc.json")
+	config := `{"rules": {"docs-markers": "warning", "back-to-top": "off", "heading-increment": "error"}}`
+	if err := os.WriteFile(configFile, []byte(config), 0644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	setupAnalyzeTest()
+	RootCmd.SetArgs([]string{"analyze", "--fix", "--config", configFile, "--file", testFile})
+	err := RootCmd.Execute()
+	if !errors.Is(err, errAnalyzeFindings) || !strings.Contains(err.Error(), "1 error(s)") {
+		t.Errorf("the skipped heading level should be the only error, got %v", err)
+	}
+	updated, _ := os.ReadFile(testFile)
+	if strings.Contains(string(updated), "back to top</a>)</p>\n\n<!-- END_DOCS") {
+		t.Errorf("a disabled rule should not be fixed, got:\n%s", updated)
+	}
+	if !strings.Contains(string(updated), beginDocsMarker) {
+		t.Errorf("enabled rules should still be fixed, got:\n%s", updated)
+	}
+}
+
+func TestAnalyzeCommandRejectsUnknownRules(t *testing.T) {
+	tempDir := t.TempDir()
+	testFile := filepath.Join(tempDir, "README.md")
+	if err := os.WriteFile(testFile, []byte("# Title\n"), 0644); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+	configFile := filepath.Join(tempDir, "gtoc.json")
+	if err := os.WriteFile(configFile, []byte(`{"rules": {"no-such-rule": "off"}}`), 0644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	setupAnalyzeTest()
+	RootCmd.SetArgs([]string{"analyze", "--config", configFile, "--file", testFile})
+	if err := RootCmd.Execute(); err == nil || !strings.Contains(err.Error(), "no-such-rule") {
+		t.Errorf("an unknown rule in the config should be rejected, got %v", err)
+	}
+}
+
+func TestAnalyzeCommandRejectsUnknownFormat(t *testing.T) {
+	testFile := filepath.Join(t.TempDir(), "README.md")
+	if err := os.WriteFile(testFile, []byte("# Title\n"), 0644); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+
+	setupAnalyzeTest()
+	RootCmd.SetArgs([]string{"analyze", "--format", "xml", "--file", testFile})
+	if err := RootCmd.Execute(); err == nil || !strings.Contains(err.Error(), "unknown format") {
+		t.Errorf("expected an unknown format error, got %v", err)
+	}
+}
+
+func TestAnalyzeCommandDryRunAndCheck(t *testing.T) {
+	testFile := filepath.Join(t.TempDir(), "README.md")
+	initial := "# Title\n\n## Install:\n\ntext\n"
+	if err := os.WriteFile(testFile, []byte(initial), 0644); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+
+	for _, preview := range []string{"diff", "full", "rendered"} {
+		setupAnalyzeTest()
+		RootCmd.SetArgs([]string{"analyze", "--dry-run", "--preview", preview, "--file", testFile})
+		if err := RootCmd.Execute(); err != nil {
+			t.Errorf("analyze --dry-run --preview %s failed: %v", preview, err)
+		}
+	}
+
+	setupAnalyzeTest()
+	RootCmd.SetArgs([]string{"analyze", "--check", "--file", testFile})
+	if err := RootCmd.Execute(); !errors.Is(err, errAnalyzeChanges) {
+		t.Errorf("analyze --check should fail on a file --fix would change, got %v", err)
+	}
+	if updated, _ := os.ReadFile(testFile); string(updated) != initial {
+		t.Fatalf("--dry-run and --check must not write, got:\n%s", updated)
+	}
+
+	setupAnalyzeTest()
+	RootCmd.SetArgs([]string{"analyze", "--fix", "--file", testFile})
+	if err := RootCmd.Execute(); err != nil {
+		t.Fatalf("analyze --fix failed: %v", err)
+	}
+	setupAnalyzeTest()
+	RootCmd.SetArgs([]string{"analyze", "--check", "--file", testFile})
+	if err := RootCmd.Execute(); err != nil {
+		t.Errorf("analyze --check should pass once the file is fixed, got %v", err)
+	}
+}
+
+func TestAnalyzeCommandRejectsInvalidReviewFlags(t *testing.T) {
+	testFile := filepath.Join(t.TempDir(), "README.md")
+	if err := os.WriteFile(testFile, []byte("# Title\n"), 0644); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+
+	for _, args := range [][]string{
+		{"--check", "--fix"},
+		{"--check", "--dry-run"},
+		{"--dry-run", "--preview", "side-by-side"},
+	} {
+		setupAnalyzeTest()
+		RootCmd.SetArgs(append([]string{"analyze", "--file", testFile}, args...))
+		if err := RootCmd.Execute(); err == nil {
+			t.Errorf("expected an error for %v", args)
+		}
+	}
+}
+
+func TestAnalyzeCommandBackToTopLevel(t *testing.T) {
+	const initial = "# Project\n\nIntro.\n\n## Install\n\nRun it.\n\n### From source\n\nBuild it.\n\n## Usage\n\nUse it.\n"
+	link := i18n.BackToTopLink(i18n.Default().BackToTop)
+
+	tests := []struct {
+		level string
+		want  int
+	}{
+		{"1", 1},
+		{"2", 2},
+		{"1-2", 3},
+		{"2-3", 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.level, func(t *testing.T) {
+			testFile := filepath.Join(t.TempDir(), "README.md")
+			if err := os.WriteFile(testFile, []byte(initial), 0644); err != nil {
+				t.Fatalf("failed to create test file: %v", err)
+			}
+			args := []string{"analyze", "--fix", "--back-to-top-level", tt.level, "--config", layoutOnlyConfig(t), "--file", testFile}
+
+			setupAnalyzeTest()
+			RootCmd.SetArgs(args)
+			if err := RootCmd.Execute(); err != nil {
+				t.Fatalf("analyze failed: %v", err)
+			}
+			fixed, _ := os.ReadFile(testFile)
+			if got := strings.Count(string(fixed), link); got != tt.want {
+				t.Errorf("got %d back-to-top links, want %d:\n%s", got, tt.want, fixed)
+			}
+
+			setupAnalyzeTest()
+			RootCmd.SetArgs(args)
+			if err := RootCmd.Execute(); err != nil {
+				t.Fatalf("second analyze failed: %v", err)
+			}
+			if again, _ := os.ReadFile(testFile); string(again) != string(fixed) {
+				t.Errorf("analyze should be idempotent, got:\n%s", again)
+			}
+		})
+	}
+}
+
+func TestAnalyzeCommandFixRefreshesTOC(t *testing.T) {
+	testFile := filepath.Join(t.TempDir(), "README.md")
+	writeTestFiles(t, map[string]string{
+		testFile: "# Project\n\n<!-- START_TABLE_OF_CONTENTS -->\n<!-- END_TABLE_OF_CONTENTS -->\n\n## Setup:\n\nRun it.\n",
+	})
+
+	fixed := runAnalyzeFix(t, testFile)
+	if !strings.Contains(fixed, "[Setup](#setup)") || strings.Contains(fixed, "Setup:") {
+		t.Errorf("the TOC should list the fixed heading, got:\n%s", fixed)
+	}
+}
+
+func TestAnalyzeCommandKeepsGeneratedTOCOptions(t *testing.T) {
+	testFile := filepath.Join(t.TempDir(), "README.md")
+	writeTestFiles(t, map[string]string{
+		testFile: "# Project\n\n## Install\n\n### Linux\n\nRun it.\n",
+	})
+	runAnalyzeFix(t, testFile)
+	setupGenerateTest()
+	RootCmd.SetArgs([]string{"generate", "--file", testFile, "--depth", "2"})
+	if err := RootCmd.Execute(); err != nil {
+		t.Fatalf("generate failed: %v", err)
+	}
+	content, err := os.ReadFile(testFile)
+	if err != nil {
+		t.Fatalf("failed to read generated file: %v", err)
+	}
+	generated := string(content)
+
+	for _, args := range [][]string{nil, {"--depth", "2"}} {
+		setupAnalyzeTest()
+		RootCmd.SetArgs(append([]string{"analyze", "--check", "--file", testFile}, args...))
+		if err := RootCmd.Execute(); err != nil {
+			t.Errorf("analyze --check %v should pass on an up-to-date --depth 2 TOC, got %v", args, err)
+		}
+	}
+
+	writeTestFiles(t, map[string]string{testFile: strings.Replace(generated, "## Install\n", "## Install:\n", 1)})
+	fixed := runAnalyzeFix(t, testFile, "--depth", "2")
+	if fixed != generated {
+		t.Errorf("analyze --fix --depth 2 should restore the generated file, got:\n%s", fixed)
+	}
+}
+
+func TestParseLevelRange(t *testing.T) {
+	valid := map[string]levelRange{"2": {2, 2}, "1-2": {1, 2}, " 3 - 6 ": {3, 6}}
+	for input, want := range valid {
+		if got, err := parseLevelRange(input); err != nil || got != want {
+			t.Errorf("parseLevelRange(%q) = %v, %v, want %v", input, got, err, want)
+		}
+	}
+	for _, input := range []string{"", "0", "7", "3-1", "h2", "1-"} {
+		if _, err := parseLevelRange(input); err == nil {
+			t.Errorf("parseLevelRange(%q) should fail", input)
+		}
+	}
+}
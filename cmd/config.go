@@ -0,0 +1,25 @@
+package cmd
+
+import (
+	"errors"
+	"os"
+
+	"github.com/lpsm-dev/gtoc/internal/config"
+)
+
+// configPath is the --config flag value shared by every subcommand.
+var configPath string
+
+// loadConfig reads the project configuration from --config or, when the
+// flag is unset, from .gtoc.json in the working directory if it exists.
+func loadConfig() (config.Config, error) {
+	if configPath != "" {
+		return config.Load(configPath)
+	}
+
+	cfg, err := config.Load(config.DefaultFile)
+	if errors.Is(err, os.ErrNotExist) {
+		return config.Config{}, nil
+	}
+	return cfg, err
+}
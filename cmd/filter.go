@@ -0,0 +1,27 @@
+package cmd
+
+import (
+	"github.com/lpsm-dev/gtoc/internal/generator"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// Flags selecting the headings listed in the TOC, next to --exclude.
+	includeHeadings string
+	filterMode      string
+	filterSubtree   bool
+)
+
+// addFilterFlags registers the flags that refine --exclude.
+func addFilterFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&includeHeadings, "include", "", "Comma-separated heading patterns to list; other headings are left out")
+	cmd.Flags().StringVar(&filterMode, "filter-mode", string(generator.FilterSubstring), "How --include and --exclude patterns match: substring, glob or regex (case-insensitive)")
+	cmd.Flags().BoolVar(&filterSubtree, "subtree", false, "Apply --include and --exclude matches to the matched heading's whole subtree")
+}
+
+// headingFilter returns the filter built from --include, --exclude,
+// --filter-mode and --subtree.
+func headingFilter() (generator.HeadingFilter, error) {
+	return generator.NewHeadingFilter(generator.FilterMode(filterMode),
+		parseExcludeList(includeHeadings), parseExcludeList(excludePaths), filterSubtree)
+}
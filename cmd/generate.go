@@ -1,13 +1,18 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 
 	"charm.land/glamour/v2"
+	"github.com/lpsm-dev/gtoc/internal/fsutil"
 	"github.com/lpsm-dev/gtoc/internal/generator"
+	"github.com/lpsm-dev/gtoc/internal/git"
+	"github.com/lpsm-dev/gtoc/internal/i18n"
 	"github.com/lpsm-dev/gtoc/internal/logger"
 	"github.com/spf13/cobra"
 )
@@ -19,11 +24,26 @@ var (
 	dryRun         bool
 	prettyOutput   bool
 	numberHeadings bool
+	stripNumbers   bool
+	checkOnly      bool
+	changedSince   string
+	stagedOnly     bool
+	stageUpdates   bool
+	skipUnmarked   bool
+	maxLabelLength int
 )
 
+// markdownPathspecs restricts git file selection to markdown files when no
+// explicit paths are given.
+var markdownPathspecs = []string{"*.md", "*.markdown"}
+
+// errTOCOutOfDate is returned by --check when at least one file would be
+// changed by generate.
+var errTOCOutOfDate = errors.New("table of contents is out of date")
+
 // generateCmd handles TOC generation for markdown files.
 var generateCmd = &cobra.Command{
-	Use:     "generate [file]",
+	Use:     "generate [file...]",
 	Aliases: []string{"gen"},
 	Short:   "Generate a table of contents for a markdown file",
 	Long: `Generate a table of contents based on the headings in a markdown file
@@ -31,57 +51,261 @@ and update the file with the generated table of contents.
 
 Example:
   gtoc generate README.md
+  gtoc generate README.md docs/index.md
   gtoc generate --file docs/index.md
-  gtoc generate docs/index.md --depth 3`,
-	Args: cobra.MaximumNArgs(1),
+  gtoc generate docs/index.md --depth 3
+  gtoc generate --check README.md
+  gtoc generate --strip-numbers README.md
+  gtoc generate --lang pt-BR README.md
+  gtoc generate --max-label-length 40 README.md
+  gtoc generate --include "h2:Usage,#faq" --subtree README.md
+  gtoc generate --exclude "^API$" --filter-mode regex README.md
+  gtoc generate --number-headings --number-from-level 2 --number-format I,1 README.md
+  gtoc generate --changed-since origin/main
+  gtoc generate --staged docs/`,
 	RunE: runGenerate,
 }
 
-// runGenerate resolves the target file, generates a TOC, and either previews
-// it (--dry-run) or writes it back to the file.
+// runGenerate resolves the target files and generates a TOC for each of
+// them, stopping at the first error. With --check, every file is inspected
+// and the command fails if any of them is stale.
 func runGenerate(cmd *cobra.Command, args []string) error {
-	path, err := resolveFilePath(args)
+	if err := validateGenerateFlags(); err != nil {
+		return err
+	}
+
+	paths, err := resolveFilePaths(args)
+	if err != nil {
+		return err
+	}
+	if stageUpdates {
+		if paths, err = fullyStaged(paths); err != nil {
+			return err
+		}
+	}
+
+	stale, err := generateFiles(paths)
 	if err != nil {
 		return err
 	}
 
+	if checkOnly && stale > 0 {
+		return fmt.Errorf("%w in %d file(s); run gtoc generate to update", errTOCOutOfDate, stale)
+	}
+	if stageUpdates && len(paths) > 0 {
+		return stageFiles(paths)
+	}
+	return nil
+}
+
+// generateFiles generates a TOC for each of paths, stopping at the first
+// error, and returns how many of them were stale.
+func generateFiles(paths []string) (int, error) {
+	stale := 0
+	for _, path := range paths {
+		changed, err := generateFile(path)
+		if err != nil {
+			return stale, err
+		}
+		if changed {
+			stale++
+		}
+	}
+	return stale, nil
+}
+
+// validateGenerateFlags checks that the generate flags go together.
+func validateGenerateFlags() error {
+	if numberHeadings && stripNumbers {
+		return fmt.Errorf("--number-headings and --strip-numbers cannot be used together")
+	}
+	if stageUpdates && (!stagedOnly || checkOnly || dryRun) {
+		return fmt.Errorf("--stage needs --staged and cannot be used with --check or --dry-run")
+	}
+	return nil
+}
+
+// fullyStaged returns the staged files among paths that have no unstaged
+// changes. Staging one of the others would commit changes the user left out
+// of the commit, so they are skipped with a notice.
+func fullyStaged(paths []string) ([]string, error) {
+	if len(paths) == 0 {
+		return paths, nil
+	}
+	cwd, err := os.Getwd()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get working directory: %w", err)
+	}
+	unstaged, err := git.UnstagedFiles(cwd, paths)
+	if err != nil {
+		return nil, err
+	}
+
+	partial := map[string]bool{}
+	for _, f := range unstaged {
+		partial[f] = true
+	}
+	kept := []string{}
+	for _, path := range paths {
+		if partial[path] {
+			fmt.Printf("Skipping %s: it has unstaged changes; stage or stash them and run gtoc generate on it\n", path)
+			continue
+		}
+		kept = append(kept, path)
+	}
+	return kept, nil
+}
+
+// stageFiles adds the files generate updated back to the git index.
+func stageFiles(paths []string) error {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get working directory: %w", err)
+	}
+	logger.Debug("Staging updated files", "count", len(paths))
+	return git.Add(cwd, paths)
+}
+
+// generateFile generates a TOC for a single file and either previews it
+// (--dry-run), compares it with the file on disk (--check), or writes it
+// back. It reports whether the file is out of date in --check mode.
+func generateFile(path string) (bool, error) {
 	logger.Debug("Processing file", "path", path, "depth", depth)
 
 	absFilePath, err := validateFileExists(path)
 	if err != nil {
-		return err
+		return false, err
 	}
-
-	excludeList := parseExcludeList(excludePaths)
-	if len(excludeList) > 0 {
-		logger.Debug("Using exclude paths", "paths", excludeList)
+	if skip, err := skipUnmarkedFile(absFilePath); skip || err != nil {
+		return false, err
 	}
 
 	logger.Info("Generating table of contents", "file", absFilePath)
-	gen := generator.NewGenerator(absFilePath, depth, excludeList)
+	gen, err := newFileGenerator(absFilePath)
+	if err != nil {
+		return false, err
+	}
+
+	if checkOnly {
+		return checkFile(gen, absFilePath, path)
+	}
 
-	if numberHeadings {
-		return runNumberHeadings(gen, absFilePath, path)
+	if numberHeadings || stripNumbers {
+		return false, runRewriteHeadings(gen, path)
 	}
 
 	toc, err := gen.Generate()
 	if err != nil {
-		return fmt.Errorf("failed to generate table of contents: %w", err)
+		return false, fmt.Errorf("failed to generate table of contents: %w", err)
 	}
 
 	if dryRun {
-		return previewTOC(gen, absFilePath, toc)
+		return false, previewTOC(gen, absFilePath, toc)
+	}
+
+	return false, writeTOC(gen, path, toc)
+}
+
+// newFileGenerator returns a Generator for the file at absFilePath
+// configured from the generate flags and the document's language.
+func newFileGenerator(absFilePath string) (*generator.Generator, error) {
+	opts, err := tocOptions()
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := fsutil.ReadText(absFilePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+	messages, err := documentMessages(absFilePath, content)
+	if err != nil {
+		return nil, err
+	}
+	return newTOCGenerator(absFilePath, opts, messages), nil
+}
+
+// tocOptions returns the Generator options selected by the TOC flags (see
+// addTOCFlags), the slug style and the write flags.
+func tocOptions() ([]generator.Option, error) {
+	if excludePaths != "" || includeHeadings != "" {
+		logger.Debug("Using heading filters", "include", includeHeadings, "exclude", excludePaths, "mode", filterMode)
+	}
+	filter, err := headingFilter()
+	if err != nil {
+		return nil, err
+	}
+	scheme, err := numberingScheme()
+	if err != nil {
+		return nil, err
+	}
+	slug, err := slugger()
+	if err != nil {
+		return nil, err
+	}
+
+	return []generator.Option{
+		generator.WithFilter(filter),
+		generator.WithWriteOptions(writeOptions()),
+		generator.WithNumbering(scheme),
+		generator.WithSlugger(slug),
+		generator.WithMaxLabelLength(maxLabelLength),
+	}, nil
+}
+
+// newTOCGenerator returns a Generator for the file at absFilePath limited
+// to --depth and configured with opts, from tocOptions, writing its text
+// in messages.
+func newTOCGenerator(absFilePath string, opts []generator.Option, messages i18n.Messages) *generator.Generator {
+	return generator.NewGenerator(absFilePath, depth, nil, append(slices.Clip(opts), generator.WithMessages(messages))...)
+}
+
+// checkFile reports whether generate (or generate --number-headings) would
+// change the file, without writing anything.
+func checkFile(gen *generator.Generator, absFilePath, path string) (bool, error) {
+	current, err := fsutil.ReadText(absFilePath)
+	if err != nil {
+		return false, fmt.Errorf("failed to read file: %w", err)
 	}
 
-	return writeTOC(gen, path, toc)
+	if generatedContent(gen, current) == current {
+		logger.Info("Table of contents is up to date", "path", path)
+		return false, nil
+	}
+
+	fmt.Printf("%s: table of contents is out of date\n", path)
+	return true, nil
+}
+
+// generatedContent returns current, the content of the file gen targets, as
+// generate would write it: with the TOC refreshed and, under
+// --number-headings or --strip-numbers, the headings rewritten.
+func generatedContent(gen *generator.Generator, current string) string {
+	switch {
+	case numberHeadings:
+		return gen.GenerateNumberedContent(current)
+	case stripNumbers:
+		return gen.GenerateUnnumberedContent(current)
+	}
+	return gen.GetFileWithUpdatedTOC(current, gen.GenerateFromContent(current))
 }
 
-// runNumberHeadings numbers the document's headings in place and refreshes the
-// TOC to link to them, previewing (--dry-run) or writing the result.
-func runNumberHeadings(gen *generator.Generator, absFilePath, path string) error {
-	content, err := gen.GenerateNumberedFile()
+// rewriteHeadings returns the document with its headings numbered
+// (--number-headings) or unnumbered (--strip-numbers) and the TOC refreshed.
+func rewriteHeadings(gen *generator.Generator) (string, error) {
+	if stripNumbers {
+		return gen.GenerateUnnumberedFile()
+	}
+	return gen.GenerateNumberedFile()
+}
+
+// runRewriteHeadings numbers or unnumbers the document's headings in place
+// and refreshes the TOC to link to them, previewing (--dry-run) or writing
+// the result.
+func runRewriteHeadings(gen *generator.Generator, path string) error {
+	content, err := rewriteHeadings(gen)
 	if err != nil {
-		return fmt.Errorf("failed to number headings: %w", err)
+		return fmt.Errorf("failed to rewrite headings: %w", err)
 	}
 
 	if dryRun {
@@ -90,31 +314,133 @@ func runNumberHeadings(gen *generator.Generator, absFilePath, path string) error
 		return nil
 	}
 
-	mode := os.FileMode(0644)
-	if info, statErr := os.Stat(absFilePath); statErr == nil {
-		mode = info.Mode().Perm()
-	}
-	if err := os.WriteFile(absFilePath, []byte(content), mode); err != nil {
+	if err := gen.WriteFile(content); err != nil {
 		return fmt.Errorf("failed to write file: %w", err)
 	}
 
 	logger.Info("File updated successfully", "path", path)
-	fmt.Printf("Successfully numbered headings and updated %s\n", path)
+	if stripNumbers {
+		fmt.Printf("Successfully removed heading numbers and updated %s\n", path)
+	} else {
+		fmt.Printf("Successfully numbered headings and updated %s\n", path)
+	}
 	return nil
 }
 
-// resolveFilePath returns the target file path from the positional argument
-// or the --file flag, in that priority order.
-func resolveFilePath(args []string) (string, error) {
-	if len(args) > 0 && filePath == "" {
-		filePath = args[0]
+// skipUnmarkedFile reports whether --skip-unmarked leaves out the file at
+// absFilePath because it has no TOC markers yet.
+func skipUnmarkedFile(absFilePath string) (bool, error) {
+	if !skipUnmarked {
+		return false, nil
+	}
+	content, err := os.ReadFile(absFilePath)
+	if err != nil {
+		return false, fmt.Errorf("failed to read file: %w", err)
+	}
+	if generator.HasTOC(string(content)) {
+		return false, nil
+	}
+	logger.Debug("Skipping file without TOC markers", "path", absFilePath)
+	return true, nil
+}
+
+// resolveFilePaths returns the target file paths: the --file flag value, if
+// set, followed by every positional argument. With --changed-since or
+// --staged, the paths are instead selected from git, using the arguments as
+// pathspecs.
+func resolveFilePaths(args []string) ([]string, error) {
+	if changedSince != "" || stagedOnly {
+		return gitSelectedFiles(args)
+	}
+
+	var paths []string
+	if filePath != "" {
+		paths = append(paths, filePath)
 	}
+	paths = append(paths, args...)
 
-	if filePath == "" {
-		return "", fmt.Errorf("file path is required (provide it as an argument or with --file flag)")
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("file path is required (provide it as an argument or with --file flag)")
 	}
 
-	return filePath, nil
+	return paths, nil
+}
+
+// gitSelectedFiles returns the markdown files selected by --changed-since or
+// --staged, limited to pathspecs (or every markdown file when none are
+// given). An empty selection is not an error: there is simply nothing to do.
+func gitSelectedFiles(pathspecs []string) ([]string, error) {
+	if changedSince != "" && stagedOnly {
+		return nil, fmt.Errorf("--changed-since and --staged cannot be used together")
+	}
+	if filePath != "" {
+		pathspecs = append([]string{filePath}, pathspecs...)
+	}
+	if len(pathspecs) == 0 {
+		pathspecs = markdownPathspecs
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get working directory: %w", err)
+	}
+
+	var paths []string
+	if stagedOnly {
+		paths, err = stagedMarkdownFiles(cwd, pathspecs)
+	} else {
+		paths, err = changedMarkdownFiles(cwd, pathspecs)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Debug("Selected files from git", "count", len(paths))
+	if len(paths) == 0 {
+		fmt.Println("No changed markdown files")
+	}
+	return paths, nil
+}
+
+// stagedMarkdownFiles returns the markdown files among pathspecs that are
+// staged in the index, for --staged.
+func stagedMarkdownFiles(cwd string, pathspecs []string) ([]string, error) {
+	files, err := git.StagedFiles(cwd, pathspecs)
+	if err != nil {
+		return nil, err
+	}
+	return markdownFiles(files), nil
+}
+
+// changedMarkdownFiles returns the markdown files among pathspecs that
+// changed since the --changed-since ref.
+func changedMarkdownFiles(cwd string, pathspecs []string) ([]string, error) {
+	files, err := git.ChangedFiles(cwd, changedSince, pathspecs)
+	if err != nil {
+		return nil, err
+	}
+	return markdownFiles(files), nil
+}
+
+// markdownFiles returns the files with a markdown extension, in order.
+func markdownFiles(files []string) []string {
+	paths := []string{}
+	for _, f := range files {
+		if isMarkdownFile(f) {
+			paths = append(paths, f)
+		}
+	}
+	return paths
+}
+
+// isMarkdownFile reports whether path has a markdown file extension.
+func isMarkdownFile(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".md", ".markdown":
+		return true
+	default:
+		return false
+	}
 }
 
 // validateFileExists resolves path to an absolute path and confirms the file
@@ -133,15 +459,18 @@ func validateFileExists(path string) (string, error) {
 }
 
 // parseExcludeList splits the comma-separated --exclude flag value into a
-// trimmed slice of heading text patterns.
+// trimmed slice of heading text patterns. A comma escaped as "\," is kept
+// in the pattern, which regular expressions such as "x{1\,3}" need.
 func parseExcludeList(raw string) []string {
 	if raw == "" {
 		return []string{}
 	}
 
+	const escapedComma = "\x00"
+	raw = strings.ReplaceAll(raw, `\,`, escapedComma)
 	excludeList := []string{}
 	for _, pattern := range strings.Split(raw, ",") {
-		excludeList = append(excludeList, strings.TrimSpace(pattern))
+		excludeList = append(excludeList, strings.TrimSpace(strings.ReplaceAll(pattern, escapedComma, ",")))
 	}
 	return excludeList
 }
@@ -156,12 +485,12 @@ func previewTOC(gen *generator.Generator, absFilePath, toc string) error {
 		return nil
 	}
 
-	fileContent, err := os.ReadFile(absFilePath)
+	fileContent, err := fsutil.ReadText(absFilePath)
 	if err != nil {
 		return fmt.Errorf("failed to read file: %w", err)
 	}
 
-	updatedContent := gen.GetFileWithUpdatedTOC(string(fileContent), toc)
+	updatedContent := gen.GetFileWithUpdatedTOC(fileContent, toc)
 	renderPretty(updatedContent, toc)
 	return nil
 }
@@ -172,27 +501,29 @@ func previewTOC(gen *generator.Generator, absFilePath, toc string) error {
 func renderPretty(content, toc string) {
 	logger.Info("Pretty output enabled", "render", "glamour")
 
-	// glamour v2 removed WithAutoStyle; WithEnvironmentConfig honors the
-	// GLAMOUR_STYLE env var and defaults to the dark theme.
-	r, err := glamour.NewTermRenderer(
-		glamour.WithWordWrap(100),
-		glamour.WithEnvironmentConfig(),
-	)
+	rendered, err := renderMarkdown(content)
 	if err != nil {
-		logger.Warn("Failed to create markdown renderer, falling back to plain output", "error", err)
+		logger.Warn("Failed to render content, falling back to plain output", "error", err)
 		outputMarkdown(toc)
 		return
 	}
 
 	fmt.Println("Dry run mode. The following is how the file would look with the updated TOC:")
-	rendered, err := r.Render(content)
+	fmt.Println(rendered)
+}
+
+// renderMarkdown renders markdown content for the terminal with glamour.
+func renderMarkdown(content string) (string, error) {
+	// glamour v2 removed WithAutoStyle; WithEnvironmentConfig honors the
+	// GLAMOUR_STYLE env var and defaults to the dark theme.
+	r, err := glamour.NewTermRenderer(
+		glamour.WithWordWrap(100),
+		glamour.WithEnvironmentConfig(),
+	)
 	if err != nil {
-		logger.Warn("Failed to render content, falling back to plain output", "error", err)
-		outputMarkdown(toc)
-		return
+		return "", fmt.Errorf("failed to create markdown renderer: %w", err)
 	}
-
-	fmt.Println(rendered)
+	return r.Render(content)
 }
 
 // writeTOC updates the file on disk with the generated TOC.
@@ -213,11 +544,29 @@ func outputMarkdown(toc string) {
 	fmt.Println("\n" + toc + "\n")
 }
 
+// addTOCFlags registers the flags that shape the table of contents and
+// heading numbers generate writes on cmd.
+func addTOCFlags(cmd *cobra.Command) {
+	cmd.Flags().IntVar(&depth, "depth", 0, "Maximum heading depth (0 for unlimited)")
+	cmd.Flags().StringVar(&excludePaths, "exclude", "", "Comma-separated heading patterns to exclude from the TOC, matched per --filter-mode; h2:Text targets one level and #anchor matches the anchor")
+	addFilterFlags(cmd)
+	cmd.Flags().IntVar(&maxLabelLength, "max-label-length", 0, "Shorten TOC entries longer than this many characters with an ellipsis (0 for unlimited)")
+	cmd.Flags().BoolVar(&numberHeadings, "number-headings", false, "Number the document's headings in place (# -> 1., ## -> 1.1., ...) and link the TOC to them")
+	addNumberingFlags(cmd)
+}
+
 func init() {
 	generateCmd.Flags().StringVar(&filePath, "file", "", "Path to the markdown file to update")
-	generateCmd.Flags().IntVar(&depth, "depth", 0, "Maximum heading depth (0 for unlimited)")
-	generateCmd.Flags().StringVar(&excludePaths, "exclude", "", "Comma-separated heading texts to exclude from the TOC (case-insensitive substring match)")
+	addTOCFlags(generateCmd)
 	generateCmd.Flags().BoolVar(&dryRun, "dry-run", false, "Preview changes without writing")
 	generateCmd.Flags().BoolVar(&prettyOutput, "pretty", false, "Render output with formatting and show full file in dry-run mode")
-	generateCmd.Flags().BoolVar(&numberHeadings, "number-headings", false, "Number the document's headings in place (# -> 1., ## -> 1.1., ...) and link the TOC to them")
+	generateCmd.Flags().StringVar(&changedSince, "changed-since", "", "Only process markdown files changed since this git ref (e.g. origin/main)")
+	generateCmd.Flags().BoolVar(&stagedOnly, "staged", false, "Only process markdown files staged in the git index")
+	generateCmd.Flags().BoolVar(&stageUpdates, "stage", false, "With --staged, add the updated files back to the index, skipping files that also have unstaged changes")
+	generateCmd.Flags().BoolVar(&skipUnmarked, "skip-unmarked", false, "Skip files without TOC markers instead of adding a table of contents to them")
+	generateCmd.Flags().BoolVar(&stripNumbers, "strip-numbers", false, "Remove outline numbers from the document's headings, fixing links to the numbered anchors")
+	addLangFlag(generateCmd)
+	addSlugFlag(generateCmd)
+	addWriteFlags(generateCmd)
+	generateCmd.Flags().BoolVar(&checkOnly, "check", false, "Exit with a non-zero status if any file's table of contents is out of date, without writing")
 }
@@ -1,10 +1,14 @@
 package cmd
 
 import (
+	"errors"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"strings"
 	"testing"
+
+	"github.com/lpsm-dev/gtoc/internal/git"
 )
 
 const generateTestContent = `# First Heading
@@ -24,11 +28,37 @@ Content for the third heading.
 // flag variables to a known state before each test.
 func setupGenerateTest() {
 	resetRootCmd()
+	resetTOCFlags()
 	filePath = ""
-	depth = 0
-	excludePaths = ""
 	dryRun = false
 	prettyOutput = false
+	stripNumbers = false
+	checkOnly = false
+	changedSince = ""
+	stagedOnly = false
+	stageUpdates = false
+	skipUnmarked = false
+	language = ""
+	slugStyle = "github"
+	backupFiles = false
+	noFollowSymlinks = false
+}
+
+// resetTOCFlags resets the flags addTOCFlags registers, which generate
+// shares with the commands that generate or check a TOC.
+func resetTOCFlags() {
+	depth = 0
+	excludePaths = ""
+	includeHeadings = ""
+	filterMode = "substring"
+	filterSubtree = false
+	maxLabelLength = 0
+	numberHeadings = false
+	numberFormat = ""
+	numberSeparator = ""
+	numberPrefix = ""
+	numberFromLevel = 0
+	numberDepth = 0
 }
 
 func TestGenerateCommandUpdatesFile(t *testing.T) {
@@ -162,3 +192,444 @@ func TestGenerateCommandMissingFile(t *testing.T) {
 		t.Error("expected an error for a missing file, got nil")
 	}
 }
+
+func TestGenerateCommandMultipleFiles(t *testing.T) {
+	tempDir := t.TempDir()
+	first := filepath.Join(tempDir, "first.md")
+	second := filepath.Join(tempDir, "second.md")
+	for _, f := range []string{first, second} {
+		if err := os.WriteFile(f, []byte(generateTestContent), 0644); err != nil {
+			t.Fatalf("failed to create test file: %v", err)
+		}
+	}
+
+	setupGenerateTest()
+	RootCmd.SetArgs([]string{"generate", first, second})
+
+	if err := RootCmd.Execute(); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	for _, f := range []string{first, second} {
+		updated, err := os.ReadFile(f)
+		if err != nil {
+			t.Fatalf("failed to read updated file: %v", err)
+		}
+		if !strings.Contains(string(updated), "<!-- START_TABLE_OF_CONTENTS -->") {
+			t.Errorf("%s should have been updated", filepath.Base(f))
+		}
+	}
+}
+
+func TestGenerateCommandCheck(t *testing.T) {
+	tempDir := t.TempDir()
+	testFile := filepath.Join(tempDir, "test.md")
+	if err := os.WriteFile(testFile, []byte(generateTestContent), 0644); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+
+	setupGenerateTest()
+	RootCmd.SetArgs([]string{"generate", "--check", testFile})
+
+	err := RootCmd.Execute()
+	if !errors.Is(err, errTOCOutOfDate) {
+		t.Fatalf("--check on a stale file: error = %v, want errTOCOutOfDate", err)
+	}
+
+	unchanged, err := os.ReadFile(testFile)
+	if err != nil {
+		t.Fatalf("failed to read file: %v", err)
+	}
+	if string(unchanged) != generateTestContent {
+		t.Error("--check should not modify the file")
+	}
+
+	setupGenerateTest()
+	RootCmd.SetArgs([]string{"generate", testFile})
+	if err := RootCmd.Execute(); err != nil {
+		t.Fatalf("generate failed: %v", err)
+	}
+
+	setupGenerateTest()
+	RootCmd.SetArgs([]string{"generate", "--check", testFile})
+	if err := RootCmd.Execute(); err != nil {
+		t.Errorf("--check on an up-to-date file should succeed, got %v", err)
+	}
+}
+
+// commitAll stages and commits everything in dir with a fixed identity.
+func commitAll(t *testing.T, dir, message string) {
+	t.Helper()
+	runGit(t, dir, "add", "-A")
+	runGit(t, dir, "-c", "user.name=gtoc", "-c", "user.email=gtoc@example.com", "commit", "-q", "-m", message)
+}
+
+func TestGenerateCommandStaged(t *testing.T) {
+	dir := initGitRepo(t)
+	staged := filepath.Join(dir, "staged.md")
+	unstaged := filepath.Join(dir, "unstaged.md")
+	for _, f := range []string{staged, unstaged} {
+		if err := os.WriteFile(f, []byte(generateTestContent), 0644); err != nil {
+			t.Fatalf("failed to create test file: %v", err)
+		}
+	}
+	runGit(t, dir, "add", "staged.md")
+
+	setupGenerateTest()
+	RootCmd.SetArgs([]string{"generate", "--staged"})
+	if err := RootCmd.Execute(); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	if content, _ := os.ReadFile(staged); !strings.Contains(string(content), "<!-- START_TABLE_OF_CONTENTS -->") {
+		t.Error("--staged should update staged markdown files")
+	}
+	if content, _ := os.ReadFile(unstaged); string(content) != generateTestContent {
+		t.Error("--staged should leave unstaged files untouched")
+	}
+}
+
+func TestGenerateCommandStageSkipsUnmarkedAndPartialFiles(t *testing.T) {
+	dir := initGitRepo(t)
+	marked := "<!-- START_TABLE_OF_CONTENTS -->\n<!-- END_TABLE_OF_CONTENTS -->\n\n" + generateTestContent
+	files := map[string]string{"marked.md": marked, "partial.md": marked, "CHANGELOG.md": generateTestContent}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("failed to create %s: %v", name, err)
+		}
+	}
+	runGit(t, dir, "add", "-A")
+	partialEdit := marked + "\nNot staged yet.\n"
+	if err := os.WriteFile(filepath.Join(dir, "partial.md"), []byte(partialEdit), 0644); err != nil {
+		t.Fatalf("failed to edit partial.md: %v", err)
+	}
+
+	setupGenerateTest()
+	RootCmd.SetArgs([]string{"generate", "--staged", "--skip-unmarked", "--stage"})
+	if err := RootCmd.Execute(); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	if staged := gitOutput(t, dir, "show", ":marked.md"); !strings.Contains(staged, "[First Heading](#first-heading)") {
+		t.Errorf("--stage should stage the updated TOC, index has:\n%s", staged)
+	}
+	if staged := gitOutput(t, dir, "show", ":partial.md"); staged != marked {
+		t.Errorf("a partially staged file must not be restaged, index has:\n%s", staged)
+	}
+	if content, _ := os.ReadFile(filepath.Join(dir, "partial.md")); string(content) != partialEdit {
+		t.Errorf("a partially staged file must be left alone, got:\n%s", content)
+	}
+	if content, _ := os.ReadFile(filepath.Join(dir, "CHANGELOG.md")); string(content) != generateTestContent {
+		t.Errorf("--skip-unmarked should leave files without markers alone, got:\n%s", content)
+	}
+
+	setupGenerateTest()
+	RootCmd.SetArgs([]string{"generate", "--stage", "marked.md"})
+	if err := RootCmd.Execute(); err == nil {
+		t.Error("--stage without --staged should fail")
+	}
+}
+
+// gitOutput runs a git command in dir and returns its standard output,
+// failing the test if it errors.
+func gitOutput(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		t.Fatalf("git %s failed: %v", strings.Join(args, " "), err)
+	}
+	return string(out)
+}
+
+func TestGenerateCommandChangedSince(t *testing.T) {
+	dir := initGitRepo(t)
+	old := filepath.Join(dir, "old.md")
+	if err := os.WriteFile(old, []byte(generateTestContent), 0644); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+	commitAll(t, dir, "initial")
+	runGit(t, dir, "tag", "base")
+
+	changed := filepath.Join(dir, "docs", "changed.md")
+	if err := os.MkdirAll(filepath.Dir(changed), 0755); err != nil {
+		t.Fatalf("failed to create docs dir: %v", err)
+	}
+	if err := os.WriteFile(changed, []byte(generateTestContent), 0644); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("# Not markdown\n"), 0644); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+	commitAll(t, dir, "second")
+
+	setupGenerateTest()
+	RootCmd.SetArgs([]string{"generate", "--changed-since", "base"})
+	if err := RootCmd.Execute(); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	if content, _ := os.ReadFile(changed); !strings.Contains(string(content), "<!-- START_TABLE_OF_CONTENTS -->") {
+		t.Error("--changed-since should update markdown files changed after the ref")
+	}
+	if content, _ := os.ReadFile(old); string(content) != generateTestContent {
+		t.Error("--changed-since should leave files unchanged since the ref untouched")
+	}
+	if content, _ := os.ReadFile(filepath.Join(dir, "notes.txt")); string(content) != "# Not markdown\n" {
+		t.Error("--changed-since should only process markdown files")
+	}
+}
+
+func TestGenerateCommandChangedSinceErrors(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	t.Run("outside a git work tree", func(t *testing.T) {
+		t.Chdir(t.TempDir())
+		setupGenerateTest()
+		RootCmd.SetArgs([]string{"generate", "--staged"})
+		if err := RootCmd.Execute(); !errors.Is(err, git.ErrNotWorkTree) {
+			t.Errorf("error = %v, want git.ErrNotWorkTree", err)
+		}
+	})
+
+	t.Run("unknown ref", func(t *testing.T) {
+		initGitRepo(t)
+		setupGenerateTest()
+		RootCmd.SetArgs([]string{"generate", "--changed-since", "no-such-ref"})
+		err := RootCmd.Execute()
+		if err == nil || !strings.Contains(err.Error(), "unknown git ref") {
+			t.Errorf("error = %v, want an unknown git ref error", err)
+		}
+	})
+}
+
+func TestGenerateCommandStripNumbers(t *testing.T) {
+	tempDir := t.TempDir()
+	testFile := filepath.Join(tempDir, "test.md")
+	numbered := "# 1. First\n\nSee [Sub](#11-sub).\n\n## 1.1. Sub\n"
+	if err := os.WriteFile(testFile, []byte(numbered), 0644); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+
+	setupGenerateTest()
+	RootCmd.SetArgs([]string{"generate", "--strip-numbers", testFile})
+	if err := RootCmd.Execute(); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	updated, err := os.ReadFile(testFile)
+	if err != nil {
+		t.Fatalf("failed to read updated file: %v", err)
+	}
+	content := string(updated)
+	for _, want := range []string{"# First\n", "## Sub\n", "See [Sub](#sub)."} {
+		if !strings.Contains(content, want) {
+			t.Errorf("--strip-numbers result should contain %q, got:\n%s", want, content)
+		}
+	}
+
+	setupGenerateTest()
+	RootCmd.SetArgs([]string{"generate", "--strip-numbers", "--number-headings", testFile})
+	if err := RootCmd.Execute(); err == nil {
+		t.Error("--strip-numbers and --number-headings together should be rejected")
+	}
+}
+
+func TestGenerateCommandNumberingScheme(t *testing.T) {
+	tempDir := t.TempDir()
+	testFile := filepath.Join(tempDir, "test.md")
+	if err := os.WriteFile(testFile, []byte("# Title\n\n## Intro\n\n### Scope\n\n## Usage\n"), 0644); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+
+	setupGenerateTest()
+	RootCmd.SetArgs([]string{"generate", "--number-headings", "--number-from-level", "2",
+		"--number-format", "I,a", "--number-separator", "-", testFile})
+	if err := RootCmd.Execute(); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	updated, err := os.ReadFile(testFile)
+	if err != nil {
+		t.Fatalf("failed to read updated file: %v", err)
+	}
+	content := string(updated)
+	for _, want := range []string{"# Title\n", "## I. Intro\n", "### I-a. Scope\n", "## II. Usage\n"} {
+		if !strings.Contains(content, want) {
+			t.Errorf("numbered result should contain %q, got:\n%s", want, content)
+		}
+	}
+
+	setupGenerateTest()
+	RootCmd.SetArgs([]string{"generate", "--number-headings", "--number-format", "1,z", testFile})
+	if err := RootCmd.Execute(); err == nil {
+		t.Error("an unknown --number-format token should be rejected")
+	}
+}
+
+func TestGenerateCommandLocalizesBackToTop(t *testing.T) {
+	tempDir := t.TempDir()
+	portuguese := filepath.Join(tempDir, "README.pt-BR.md")
+	english := filepath.Join(tempDir, "README_en.md")
+	spanish := filepath.Join(tempDir, "guide.md")
+	for path, content := range map[string]string{
+		portuguese: "# Título\n",
+		english:    "# Title\n",
+		spanish:    "---\nlang: es\n---\n\n# Guía\n",
+	} {
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to create test file: %v", err)
+		}
+	}
+
+	setupGenerateTest()
+	RootCmd.SetArgs([]string{"generate", portuguese, english, spanish})
+	if err := RootCmd.Execute(); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	for path, want := range map[string]string{
+		portuguese: ">voltar ao topo</a>",
+		english:    ">back to top</a>",
+		spanish:    ">volver arriba</a>",
+	} {
+		updated, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("failed to read updated file: %v", err)
+		}
+		if !strings.Contains(string(updated), want) {
+			t.Errorf("%s should contain %q, got:\n%s", filepath.Base(path), want, updated)
+		}
+	}
+
+	setupGenerateTest()
+	RootCmd.SetArgs([]string{"generate", "--lang", "xx", english})
+	if err := RootCmd.Execute(); err == nil {
+		t.Error("an unsupported --lang should be rejected")
+	}
+}
+
+func TestGenerateCommandConfigMessages(t *testing.T) {
+	tempDir := t.TempDir()
+	testFile := filepath.Join(tempDir, "doc.md")
+	if err := os.WriteFile(testFile, []byte("# Title\n"), 0644); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+	configFile := filepath.Join(tempDir, "gtoc.json")
+	config := `{"lang": "pt-BR", "messages": {"pt-BR": {"backToTop": "subir"}}}`
+	if err := os.WriteFile(configFile, []byte(config), 0644); err != nil {
+		t.Fatalf("failed to create config file: %v", err)
+	}
+
+	setupGenerateTest()
+	RootCmd.SetArgs([]string{"generate", "--config", configFile, testFile})
+	if err := RootCmd.Execute(); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	updated, err := os.ReadFile(testFile)
+	if err != nil {
+		t.Fatalf("failed to read updated file: %v", err)
+	}
+	if !strings.Contains(string(updated), ">subir</a>") {
+		t.Errorf("config messages should override the built-in text, got:\n%s", updated)
+	}
+}
+
+func TestGenerateCommandASCIISlugs(t *testing.T) {
+	testFile := filepath.Join(t.TempDir(), "test.md")
+	if err := os.WriteFile(testFile, []byte("# Instalação\n"), 0644); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+
+	setupGenerateTest()
+	RootCmd.SetArgs([]string{"generate", "--slug", "ascii", testFile})
+	if err := RootCmd.Execute(); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	updated, err := os.ReadFile(testFile)
+	if err != nil {
+		t.Fatalf("failed to read updated file: %v", err)
+	}
+	if !strings.Contains(string(updated), "[Instalação](#instalacao)") {
+		t.Errorf("--slug ascii should transliterate anchors, got:\n%s", updated)
+	}
+
+	setupGenerateTest()
+	RootCmd.SetArgs([]string{"generate", "--slug", "kebab", testFile})
+	if err := RootCmd.Execute(); err == nil {
+		t.Error("an unknown --slug style should be rejected")
+	}
+}
+
+func TestGenerateCommandMaxLabelLength(t *testing.T) {
+	content := "# Install\n\n# A very long heading that reads like a sentence\n"
+	testFile := filepath.Join(t.TempDir(), "test.md")
+	if err := os.WriteFile(testFile, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+
+	setupGenerateTest()
+	RootCmd.SetArgs([]string{"generate", "--max-label-length", "20", testFile})
+	if err := RootCmd.Execute(); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	updated, err := os.ReadFile(testFile)
+	if err != nil {
+		t.Fatalf("failed to read updated file: %v", err)
+	}
+	for _, entry := range []string{
+		"[Install](#install)",
+		"[A very long heading…](#a-very-long-heading-that-reads-like-a-sentence)",
+	} {
+		if !strings.Contains(string(updated), entry) {
+			t.Errorf("updated file should contain %q, got:\n%s", entry, updated)
+		}
+	}
+}
+
+func TestGenerateCommandHeadingFilters(t *testing.T) {
+	content := "# Guide\n\n## API\n\n### Endpoints\n\n## Rapid Prototyping\n\n## FAQ\n"
+	testFile := filepath.Join(t.TempDir(), "test.md")
+	if err := os.WriteFile(testFile, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+
+	setupGenerateTest()
+	RootCmd.SetArgs([]string{"generate", "--exclude", "^api$,^faq$", "--filter-mode", "regex", "--subtree", testFile})
+	if err := RootCmd.Execute(); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	updated, err := os.ReadFile(testFile)
+	if err != nil {
+		t.Fatalf("failed to read updated file: %v", err)
+	}
+	if !strings.Contains(string(updated), "[Rapid Prototyping](#rapid-prototyping)") {
+		t.Errorf("a regex exclusion should not hide headings merely containing it, got:\n%s", updated)
+	}
+	for _, hidden := range []string{"[API]", "[Endpoints]", "[FAQ]"} {
+		if strings.Contains(string(updated), hidden) {
+			t.Errorf("TOC should not list %s, got:\n%s", hidden, updated)
+		}
+	}
+
+	setupGenerateTest()
+	RootCmd.SetArgs([]string{"generate", "--include", "x", "--filter-mode", "fuzzy", testFile})
+	if err := RootCmd.Execute(); err == nil {
+		t.Error("an unknown --filter-mode should be rejected")
+	}
+}
+
+func TestParseExcludeListKeepsEscapedCommas(t *testing.T) {
+	got := parseExcludeList(`api, x{1\,3} ,faq`)
+	want := []string{"api", "x{1,3}", "faq"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("parseExcludeList = %q, want %q", got, want)
+	}
+}
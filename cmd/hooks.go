@@ -0,0 +1,390 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/lpsm-dev/gtoc/internal/fsutil"
+	"github.com/lpsm-dev/gtoc/internal/git"
+	"github.com/lpsm-dev/gtoc/internal/logger"
+	"github.com/spf13/cobra"
+)
+
+// hookSignature marks every file written by `gtoc hooks install`, so
+// uninstall only ever removes files gtoc owns.
+const hookSignature = "managed by gtoc hooks install"
+
+// preCommitConfigFile is the file a repository configures the pre-commit
+// framework's hooks in.
+const preCommitConfigFile = ".pre-commit-config.yaml"
+
+// The comments delimiting the gtoc entry install adds to the pre-commit
+// framework configuration, so uninstall removes only that entry.
+const (
+	preCommitBeginMarker = "# BEGIN gtoc hook, " + hookSignature + "; remove with `gtoc hooks uninstall --pre-commit`"
+	preCommitEndMarker   = "# END gtoc hook"
+)
+
+// preCommitReposPattern matches the line opening the block-style repos list
+// of a pre-commit framework configuration.
+var preCommitReposPattern = regexp.MustCompile(`^repos:\s*(#.*)?$`)
+
+var (
+	hookPreCommit bool
+	hookCheck     bool
+	hookForce     bool
+)
+
+// hooksCmd groups the commands that manage gtoc's git hook integration.
+var hooksCmd = &cobra.Command{
+	Use:   "hooks",
+	Short: "Install or remove the gtoc git pre-commit hook",
+	Long: `Manage a git pre-commit hook that keeps the table of contents of staged
+markdown files up to date.
+
+The hook only touches staged markdown files that already have TOC markers,
+so changelogs and other pages without a table of contents are left alone.
+By default it regenerates their TOCs and re-stages them, skipping files with
+unstaged changes so those are never committed by accident. With --check it
+only verifies the TOCs and blocks the commit when one is stale.
+
+An existing hook that gtoc did not write is only replaced with --force,
+which keeps it next to the new one with a .bak suffix; uninstall puts it
+back. With --pre-commit a local gtoc entry is added to the repos of the
+pre-commit framework's .pre-commit-config.yaml instead, creating the file
+when there is none, and uninstall removes only that entry.
+
+Example:
+  gtoc hooks install
+  gtoc hooks install --check
+  gtoc hooks install --pre-commit
+  gtoc hooks uninstall`,
+}
+
+// hooksInstallCmd writes the gtoc pre-commit hook.
+var hooksInstallCmd = &cobra.Command{
+	Use:   "install",
+	Short: "Install the gtoc pre-commit hook",
+	Args:  cobra.NoArgs,
+	RunE:  runHooksInstall,
+}
+
+// hooksUninstallCmd removes a previously installed gtoc pre-commit hook.
+var hooksUninstallCmd = &cobra.Command{
+	Use:   "uninstall",
+	Short: "Remove the gtoc pre-commit hook",
+	Args:  cobra.NoArgs,
+	RunE:  runHooksUninstall,
+}
+
+// runHooksInstall writes either the git hook script or the gtoc entry of
+// the pre-commit framework configuration.
+func runHooksInstall(cmd *cobra.Command, args []string) error {
+	if hookPreCommit {
+		return installPreCommitEntry()
+	}
+	return installGitHook()
+}
+
+// runHooksUninstall removes what install wrote. It is a no-op when nothing
+// is installed and refuses to delete a hook gtoc did not write.
+func runHooksUninstall(cmd *cobra.Command, args []string) error {
+	if hookPreCommit {
+		return uninstallPreCommitEntry()
+	}
+	return uninstallGitHook()
+}
+
+// installGitHook writes the git hook script, refusing to replace a hook
+// gtoc does not own unless --force is set, in which case the original is
+// kept next to it with a .bak suffix.
+func installGitHook() error {
+	path, err := gitHookPath()
+	if err != nil {
+		return err
+	}
+
+	if existing, err := os.ReadFile(path); err == nil && !isManagedHook(existing) {
+		if !hookForce {
+			return fmt.Errorf("%s already exists and was not installed by gtoc (use --force to back it up and replace it)", path)
+		}
+		if err := backUpHook(path); err != nil {
+			return err
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create hooks directory: %w", err)
+	}
+
+	logger.Debug("Writing hook", "path", path)
+	if err := os.WriteFile(path, []byte(gitHookScript(hookCheck)), 0755); err != nil {
+		return fmt.Errorf("failed to write hook: %w", err)
+	}
+	// WriteFile does not change the mode of an existing file.
+	if err := os.Chmod(path, 0755); err != nil {
+		return fmt.Errorf("failed to set hook permissions: %w", err)
+	}
+
+	fmt.Printf("Installed gtoc pre-commit hook at %s\n", path)
+	return nil
+}
+
+// backUpHook moves the hook at path, which gtoc did not write, to path +
+// ".bak", refusing to overwrite an earlier backup.
+func backUpHook(path string) error {
+	backup := path + ".bak"
+	if _, err := os.Lstat(backup); err == nil {
+		return fmt.Errorf("%s already exists; move it away before replacing %s", backup, path)
+	}
+	if err := os.Rename(path, backup); err != nil {
+		return fmt.Errorf("failed to back up hook: %w", err)
+	}
+	fmt.Printf("Backed up the existing pre-commit hook to %s\n", backup)
+	return nil
+}
+
+// uninstallGitHook removes the git hook script written by install and
+// restores the hook it replaced, if any.
+func uninstallGitHook() error {
+	path, err := gitHookPath()
+	if err != nil {
+		return err
+	}
+
+	existing, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		fmt.Println("No gtoc pre-commit hook installed")
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read hook: %w", err)
+	}
+
+	if !isManagedHook(existing) {
+		return fmt.Errorf("%s was not installed by gtoc; refusing to remove it", path)
+	}
+
+	if err := os.Remove(path); err != nil {
+		return fmt.Errorf("failed to remove hook: %w", err)
+	}
+	fmt.Printf("Removed gtoc pre-commit hook from %s\n", path)
+
+	backup := path + ".bak"
+	if _, err := os.Lstat(backup); err != nil {
+		return nil
+	}
+	if err := os.Rename(backup, path); err != nil {
+		return fmt.Errorf("failed to restore hook: %w", err)
+	}
+	fmt.Printf("Restored the previous pre-commit hook from %s\n", backup)
+	return nil
+}
+
+// installPreCommitEntry adds the gtoc entry to the pre-commit framework
+// configuration, creating the file when there is none and replacing an
+// entry written by an earlier install. The rest of the file is kept as is.
+func installPreCommitEntry() error {
+	path, err := preCommitConfigPath()
+	if err != nil {
+		return err
+	}
+
+	snap, err := fsutil.ReadSnapshot(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to read %s: %w", preCommitConfigFile, err)
+	}
+	config, err := withPreCommitEntry(snap.Text, hookCheck)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+
+	logger.Debug("Writing hook", "path", path)
+	if err := fsutil.WriteText(path, config, fsutil.Options{Expect: snap.Fingerprint}); err != nil {
+		return fmt.Errorf("failed to write %s: %w", preCommitConfigFile, err)
+	}
+
+	fmt.Printf("Installed gtoc pre-commit hook at %s\n", path)
+	return nil
+}
+
+// uninstallPreCommitEntry removes the gtoc entry from the pre-commit
+// framework configuration, and the file itself when the entry was all it
+// configured.
+func uninstallPreCommitEntry() error {
+	path, err := preCommitConfigPath()
+	if err != nil {
+		return err
+	}
+
+	snap, err := fsutil.ReadSnapshot(path)
+	if errors.Is(err, os.ErrNotExist) || err == nil && !isManagedHook([]byte(snap.Text)) {
+		fmt.Println("No gtoc pre-commit hook installed")
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", preCommitConfigFile, err)
+	}
+
+	config := withoutPreCommitEntry(snap.Text)
+	if strings.TrimSpace(config) == "repos:" {
+		err = os.Remove(path)
+	} else {
+		err = fsutil.WriteText(path, config, fsutil.Options{Expect: snap.Fingerprint})
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update %s: %w", preCommitConfigFile, err)
+	}
+
+	fmt.Printf("Removed gtoc pre-commit hook from %s\n", path)
+	return nil
+}
+
+// gitHookPath returns the path of the pre-commit hook of the current
+// repository.
+func gitHookPath() (string, error) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return "", fmt.Errorf("failed to get working directory: %w", err)
+	}
+	hooksDir, err := git.HooksDir(cwd)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(hooksDir, "pre-commit"), nil
+}
+
+// preCommitConfigPath returns the path of the pre-commit framework
+// configuration at the root of the current repository.
+func preCommitConfigPath() (string, error) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return "", fmt.Errorf("failed to get working directory: %w", err)
+	}
+	root, err := git.TopLevel(cwd)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(root, preCommitConfigFile), nil
+}
+
+// isManagedHook reports whether content was written by `gtoc hooks install`.
+func isManagedHook(content []byte) bool {
+	return strings.Contains(string(content), hookSignature)
+}
+
+// gitHookScript returns a POSIX shell pre-commit hook for the staged
+// markdown files with TOC markers. In update mode it regenerates their TOCs
+// and re-stages the files without unstaged changes; in check mode it fails
+// the commit when any of them is stale.
+func gitHookScript(check bool) string {
+	mode := "--stage"
+	if check {
+		mode = "--check"
+	}
+	return "#!/bin/sh\n" +
+		"# " + hookSignature + "; remove with `gtoc hooks uninstall`.\n" +
+		"exec gtoc generate --staged --skip-unmarked " + mode + "\n"
+}
+
+// withPreCommitEntry returns config, the text of a pre-commit framework
+// configuration, with the gtoc entry first in its repos list, or a new
+// configuration holding only that entry when config is empty.
+func withPreCommitEntry(config string, check bool) (string, error) {
+	config = withoutPreCommitEntry(config)
+	if strings.TrimSpace(config) == "" {
+		return "repos:\n" + preCommitEntry(check, "  "), nil
+	}
+
+	lines := strings.SplitAfter(config, "\n")
+	for i, line := range lines {
+		if !preCommitReposPattern.MatchString(line) {
+			continue
+		}
+		if !strings.HasSuffix(line, "\n") {
+			lines[i] += "\n"
+		}
+		entry := preCommitEntry(check, listIndent(lines[i+1:]))
+		return strings.Join(lines[:i+1], "") + entry + strings.Join(lines[i+1:], ""), nil
+	}
+	return "", errors.New("no block-style \"repos:\" list to add the gtoc hook to; add it by hand")
+}
+
+// withoutPreCommitEntry returns config without the gtoc entry that
+// withPreCommitEntry adds, or config itself when it has none.
+func withoutPreCommitEntry(config string) string {
+	var kept []string
+	inEntry := false
+	for _, line := range strings.SplitAfter(config, "\n") {
+		switch trimmed := strings.TrimSpace(line); {
+		case trimmed == preCommitBeginMarker:
+			inEntry = true
+		case inEntry:
+			inEntry = trimmed != preCommitEndMarker
+		default:
+			kept = append(kept, line)
+		}
+	}
+	return strings.Join(kept, "")
+}
+
+// listIndent returns the indentation of the first item of the YAML list
+// starting at lines, or two spaces when the list is empty.
+func listIndent(lines []string) string {
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		if strings.HasPrefix(trimmed, "- ") {
+			return line[:len(line)-len(strings.TrimLeft(line, " "))]
+		}
+		break
+	}
+	return "  "
+}
+
+// preCommitEntry returns the gtoc entry of a pre-commit framework repos
+// list, indented by indent and delimited by marker comments. pre-commit
+// passes the staged files matching "files" as arguments, stashes unstaged
+// changes while it runs and re-stages nothing itself: a modified file fails
+// the run so the user can review and add it.
+func preCommitEntry(check bool, indent string) string {
+	entry := "gtoc generate --skip-unmarked"
+	if check {
+		entry += " --check"
+	}
+
+	lines := []string{
+		preCommitBeginMarker,
+		"- repo: local",
+		"  hooks:",
+		"    - id: gtoc",
+		"      name: gtoc",
+		"      description: Keep markdown tables of contents up to date",
+		"      entry: " + entry,
+		"      language: system",
+		"      files: \\.(md|markdown)$",
+		preCommitEndMarker,
+	}
+	var sb strings.Builder
+	for _, line := range lines {
+		sb.WriteString(indent + line + "\n")
+	}
+	return sb.String()
+}
+
+func init() {
+	for _, c := range []*cobra.Command{hooksInstallCmd, hooksUninstallCmd} {
+		c.Flags().BoolVar(&hookPreCommit, "pre-commit", false, "Manage a local gtoc hook in "+preCommitConfigFile+" for the pre-commit framework instead of a git hook")
+	}
+	hooksInstallCmd.Flags().BoolVar(&hookCheck, "check", false, "Only verify TOCs and block the commit when one is stale, instead of regenerating them")
+	hooksInstallCmd.Flags().BoolVar(&hookForce, "force", false, "Replace an existing hook that was not installed by gtoc, keeping it with a .bak suffix")
+
+	hooksCmd.AddCommand(hooksInstallCmd)
+	hooksCmd.AddCommand(hooksUninstallCmd)
+}
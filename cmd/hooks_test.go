@@ -0,0 +1,217 @@
+package cmd
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// initGitRepo creates a fresh git repository, makes it the working
+// directory for the rest of the test, and returns its path. Tests that need
+// git are skipped when the binary is not available.
+func initGitRepo(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	dir := t.TempDir()
+	runGit(t, dir, "init", "-q")
+	t.Chdir(dir)
+	return dir
+}
+
+// runGit runs a git command in dir and fails the test if it errors.
+func runGit(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git %s failed: %v\n%s", strings.Join(args, " "), err, out)
+	}
+}
+
+// setupHooksTest resets RootCmd and the hooks command's flag variables.
+func setupHooksTest() {
+	resetRootCmd()
+	hookPreCommit = false
+	hookCheck = false
+	hookForce = false
+}
+
+func TestHooksInstallAndUninstall(t *testing.T) {
+	dir := initGitRepo(t)
+	hookPath := filepath.Join(dir, ".git", "hooks", "pre-commit")
+
+	setupHooksTest()
+	RootCmd.SetArgs([]string{"hooks", "install"})
+	if err := RootCmd.Execute(); err != nil {
+		t.Fatalf("hooks install failed: %v", err)
+	}
+
+	content, err := os.ReadFile(hookPath)
+	if err != nil {
+		t.Fatalf("hook was not written: %v", err)
+	}
+	if !strings.Contains(string(content), "gtoc generate --staged --skip-unmarked --stage") {
+		t.Errorf("update-mode hook should regenerate and re-stage marked files, got:\n%s", content)
+	}
+	if info, err := os.Stat(hookPath); err == nil && info.Mode().Perm()&0100 == 0 {
+		t.Error("hook should be executable")
+	}
+
+	setupHooksTest()
+	RootCmd.SetArgs([]string{"hooks", "uninstall"})
+	if err := RootCmd.Execute(); err != nil {
+		t.Fatalf("hooks uninstall failed: %v", err)
+	}
+	if _, err := os.Stat(hookPath); !os.IsNotExist(err) {
+		t.Error("uninstall should remove the hook")
+	}
+}
+
+func TestHooksInstallCheckMode(t *testing.T) {
+	dir := initGitRepo(t)
+
+	setupHooksTest()
+	RootCmd.SetArgs([]string{"hooks", "install", "--check"})
+	if err := RootCmd.Execute(); err != nil {
+		t.Fatalf("hooks install --check failed: %v", err)
+	}
+
+	content, err := os.ReadFile(filepath.Join(dir, ".git", "hooks", "pre-commit"))
+	if err != nil {
+		t.Fatalf("hook was not written: %v", err)
+	}
+	if !strings.Contains(string(content), "gtoc generate --staged --skip-unmarked --check") || strings.Contains(string(content), "--stage\n") {
+		t.Errorf("check-mode hook should only run generate --check, got:\n%s", content)
+	}
+}
+
+func TestHooksRefusesForeignHook(t *testing.T) {
+	dir := initGitRepo(t)
+	hookPath := filepath.Join(dir, ".git", "hooks", "pre-commit")
+	foreign := "#!/bin/sh\necho custom\n"
+	if err := os.WriteFile(hookPath, []byte(foreign), 0755); err != nil {
+		t.Fatalf("failed to write foreign hook: %v", err)
+	}
+
+	setupHooksTest()
+	RootCmd.SetArgs([]string{"hooks", "install"})
+	if err := RootCmd.Execute(); err == nil {
+		t.Error("install should refuse to overwrite a hook gtoc did not write")
+	}
+
+	setupHooksTest()
+	RootCmd.SetArgs([]string{"hooks", "uninstall"})
+	if err := RootCmd.Execute(); err == nil {
+		t.Error("uninstall should refuse to remove a hook gtoc did not write")
+	}
+
+	content, _ := os.ReadFile(hookPath)
+	if string(content) != foreign {
+		t.Error("the foreign hook must be left untouched")
+	}
+
+	setupHooksTest()
+	RootCmd.SetArgs([]string{"hooks", "install", "--force"})
+	if err := RootCmd.Execute(); err != nil {
+		t.Fatalf("install --force failed: %v", err)
+	}
+	if backup, _ := os.ReadFile(hookPath + ".bak"); string(backup) != foreign {
+		t.Errorf("install --force should keep the foreign hook as %s, got %q", filepath.Base(hookPath)+".bak", backup)
+	}
+
+	setupHooksTest()
+	RootCmd.SetArgs([]string{"hooks", "uninstall"})
+	if err := RootCmd.Execute(); err != nil {
+		t.Fatalf("hooks uninstall failed: %v", err)
+	}
+	if content, _ := os.ReadFile(hookPath); string(content) != foreign {
+		t.Errorf("uninstall should restore the foreign hook, got %q", content)
+	}
+	if _, err := os.Stat(hookPath + ".bak"); !os.IsNotExist(err) {
+		t.Error("uninstall should move the backup back into place")
+	}
+}
+
+func TestHooksPreCommitFramework(t *testing.T) {
+	dir := initGitRepo(t)
+	path := filepath.Join(dir, preCommitConfigFile)
+
+	setupHooksTest()
+	RootCmd.SetArgs([]string{"hooks", "install", "--pre-commit"})
+	if err := RootCmd.Execute(); err != nil {
+		t.Fatalf("hooks install --pre-commit failed: %v", err)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("%s was not written: %v", preCommitConfigFile, err)
+	}
+	for _, want := range []string{"repos:", "- repo: local", "- id: gtoc", "entry: gtoc generate --skip-unmarked", "language: system"} {
+		if !strings.Contains(string(content), want) {
+			t.Errorf("%s should contain %q, got:\n%s", preCommitConfigFile, want, content)
+		}
+	}
+
+	setupHooksTest()
+	RootCmd.SetArgs([]string{"hooks", "uninstall", "--pre-commit"})
+	if err := RootCmd.Execute(); err != nil {
+		t.Fatalf("hooks uninstall --pre-commit failed: %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("uninstall --pre-commit should remove %s", preCommitConfigFile)
+	}
+}
+
+func TestHooksPreCommitFrameworkKeepsOtherHooks(t *testing.T) {
+	dir := initGitRepo(t)
+	path := filepath.Join(dir, preCommitConfigFile)
+	existing := "---\nrepos:\n    - repo: https://github.com/pre-commit/pre-commit-hooks\n      rev: v5.0.0\n      hooks:\n          - id: trailing-whitespace\n"
+	writeTestFiles(t, map[string]string{path: existing})
+
+	for _, args := range [][]string{nil, {"--check"}} {
+		setupHooksTest()
+		RootCmd.SetArgs(append([]string{"hooks", "install", "--pre-commit"}, args...))
+		if err := RootCmd.Execute(); err != nil {
+			t.Fatalf("hooks install --pre-commit %v failed: %v", args, err)
+		}
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read %s: %v", preCommitConfigFile, err)
+	}
+	for _, want := range []string{"repos:\n    # BEGIN gtoc hook", "\n    - repo: local\n", "entry: gtoc generate --skip-unmarked --check\n", "id: trailing-whitespace"} {
+		if !strings.Contains(string(content), want) {
+			t.Errorf("%s should contain %q, got:\n%s", preCommitConfigFile, want, content)
+		}
+	}
+	if n := strings.Count(string(content), "- id: gtoc"); n != 1 {
+		t.Errorf("reinstalling should replace the gtoc entry, got %d of them:\n%s", n, content)
+	}
+
+	setupHooksTest()
+	RootCmd.SetArgs([]string{"hooks", "uninstall", "--pre-commit"})
+	if err := RootCmd.Execute(); err != nil {
+		t.Fatalf("hooks uninstall --pre-commit failed: %v", err)
+	}
+	if content, _ := os.ReadFile(path); string(content) != existing {
+		t.Errorf("uninstall --pre-commit should only remove the gtoc entry, got:\n%s", content)
+	}
+}
+
+func TestHooksOutsideGitRepo(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	t.Chdir(t.TempDir())
+
+	setupHooksTest()
+	RootCmd.SetArgs([]string{"hooks", "install"})
+	if err := RootCmd.Execute(); err == nil {
+		t.Error("install outside a git work tree should fail")
+	}
+}
@@ -0,0 +1,197 @@
+package cmd
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/lpsm-dev/gtoc/internal/fsutil"
+	"github.com/lpsm-dev/gtoc/internal/i18n"
+	"github.com/lpsm-dev/gtoc/internal/logger"
+	"github.com/lpsm-dev/gtoc/internal/scaffold"
+	"github.com/spf13/cobra"
+)
+
+var (
+	initFile         string
+	initName         string
+	initDescription  string
+	initTemplate     string
+	initLang         string
+	initTranslations string
+	initForce        bool
+)
+
+// initTarget is a README init writes and the language it is written in.
+type initTarget struct {
+	path string
+	lang string
+}
+
+// initCmd scaffolds a new README that already follows gtoc's conventions.
+var initCmd = &cobra.Command{
+	Use:   "init",
+	Short: "Scaffold a README with gtoc markers and standard sections",
+	Long: `Create a README from a template, laid out the way analyze expects: the
+BEGIN_DOCS header with the readme-top anchor, a table of contents, the
+template's sections, back-to-top links and the END_DOCS marker.
+
+The built-in templates are "standard" (Installation, Usage, Configuration,
+Contributing and License) and "minimal" (Usage and License); --template
+also takes the path of a Go text/template file, which can use .Name,
+.Description, .Lang, .Messages and {{section "usage"}} for the standard
+section titles in the README's language.
+
+The project name comes from the module path in go.mod, or else the
+directory name, and the description from the Go package doc comment; set
+them with --name and --description. --translations also creates a
+translated sibling per language, such as README_es.md, linked from a
+language bar. Existing files are only replaced with --force.
+
+The table of contents is generated the way gtoc generate would with the
+same --depth, --exclude, numbering and other TOC flags, so a later
+generate --check with them passes.
+
+Example:
+  gtoc init
+  gtoc init --template minimal --description "A tiny CLI"
+  gtoc init --translations pt-BR,es
+  gtoc init --depth 2 --number-headings
+  gtoc init --file docs/README.md --template ./templates/service.md`,
+	RunE: runInit,
+}
+
+// runInit writes the README and its translations from the template, then
+// applies the analyze fixes to each and fills in their tables of contents.
+func runInit(cmd *cobra.Command, args []string) error {
+	targets, err := initTargets()
+	if err != nil {
+		return err
+	}
+	tmpl, err := scaffold.Load(initTemplate)
+	if err != nil {
+		return err
+	}
+
+	// Every file must exist before the fixes run, so each language bar
+	// links all of them.
+	for _, t := range targets {
+		if err := writeSkeleton(tmpl, t); err != nil {
+			return err
+		}
+	}
+	for _, t := range targets {
+		if err := finishSkeleton(t.path); err != nil {
+			return err
+		}
+		fmt.Printf("Created %s\n", t.path)
+	}
+	return nil
+}
+
+// initTargets returns the README and its translated siblings, checking
+// their languages and that none of them exists unless --force is set.
+func initTargets() ([]initTarget, error) {
+	catalog, fallback, err := messageCatalog()
+	if err != nil {
+		return nil, err
+	}
+
+	readme := initTarget{path: initFile, lang: cmp.Or(initLang, fallback, i18n.DefaultLanguage)}
+	targets := []initTarget{readme}
+	stem, ext := strings.TrimSuffix(initFile, filepath.Ext(initFile)), filepath.Ext(initFile)
+	for _, lang := range strings.FieldsFunc(initTranslations, isListSeparator) {
+		targets = append(targets, initTarget{path: stem + "_" + lang + ext, lang: lang})
+	}
+
+	for _, t := range targets {
+		if _, ok := catalog.Lookup(t.lang); !ok {
+			return nil, fmt.Errorf("unsupported language %q (available: %s)", t.lang, strings.Join(catalog.Languages(), ", "))
+		}
+		if _, err := os.Stat(t.path); err == nil && !initForce {
+			return nil, fmt.Errorf("%s already exists; use --force to replace it", t.path)
+		} else if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+	}
+	return targets, nil
+}
+
+// isListSeparator reports whether r separates the languages of
+// --translations.
+func isListSeparator(r rune) bool {
+	return r == ',' || r == ' '
+}
+
+// writeSkeleton renders the template for t and writes it. A README whose
+// language would not be detected from its name gets it in front matter.
+func writeSkeleton(tmpl string, t initTarget) error {
+	catalog, fallback, err := messageCatalog()
+	if err != nil {
+		return err
+	}
+	name, description := scaffold.DetectProject(filepath.Dir(t.path))
+	content, err := scaffold.Render(tmpl, scaffold.Data{
+		Name:        cmp.Or(initName, name),
+		Description: cmp.Or(initDescription, description),
+		Lang:        t.lang,
+		Messages:    catalog.Messages(t.lang),
+	})
+	if err != nil {
+		return err
+	}
+
+	if catalog.Messages(catalog.Resolve("", fallback, t.path, content)) != catalog.Messages(t.lang) {
+		content = "---\nlang: " + t.lang + "\n---\n" + content
+	}
+
+	logger.Debug("Writing README skeleton", "path", t.path, "lang", t.lang)
+	if err := os.MkdirAll(filepath.Dir(t.path), 0o755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+	if err := fsutil.WriteText(t.path, content, writeOptions()); err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+	return nil
+}
+
+// finishSkeleton applies the analyze fixes to the skeleton at path and
+// generates its table of contents with the TOC flags, like generate.
+func finishSkeleton(path string) error {
+	absFilePath, err := filepath.Abs(path)
+	if err != nil {
+		return fmt.Errorf("failed to resolve path: %w", err)
+	}
+	snap, err := fsutil.ReadSnapshot(absFilePath)
+	if err != nil {
+		return fmt.Errorf("failed to read file: %w", err)
+	}
+	doc, linter, err := prepareAnalysis(absFilePath, snap.Text)
+	if err != nil {
+		return err
+	}
+
+	doc = linter.Fix(doc).RefreshTOC()
+	opts := writeOptions()
+	opts.Expect = snap.Fingerprint
+	if err := fsutil.WriteText(absFilePath, doc.Content, opts); err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+	return nil
+}
+
+func init() {
+	initCmd.Flags().StringVar(&initFile, "file", "README.md", "Path of the README to create")
+	initCmd.Flags().StringVar(&initName, "name", "", "Project name (default: from go.mod or the directory name)")
+	initCmd.Flags().StringVar(&initDescription, "description", "", "Project description (default: from the Go package doc comment)")
+	initCmd.Flags().StringVar(&initTemplate, "template", scaffold.DefaultTemplate, "Built-in template (standard, minimal) or path to a template file")
+	initCmd.Flags().StringVar(&initLang, "lang", "", "Language of the README (default: the config's lang, or en)")
+	initCmd.Flags().StringVar(&initTranslations, "translations", "", "Comma-separated languages to create translated siblings in, such as pt-BR,es")
+	initCmd.Flags().BoolVar(&initForce, "force", false, "Replace existing files")
+	initCmd.Flags().StringVar(&backToTopLevel, "back-to-top-level", "1", "Heading level, or range of levels such as 1-2, whose sections end with a back-to-top link")
+	addTOCFlags(initCmd)
+	addWriteFlags(initCmd)
+}
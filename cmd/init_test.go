@@ -0,0 +1,154 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// setupInitTest resets the root command and the init flags between runs.
+func setupInitTest() {
+	resetRootCmd()
+	resetTOCFlags()
+	initFile = "README.md"
+	initName = ""
+	initDescription = ""
+	initTemplate = "standard"
+	initLang = ""
+	initTranslations = ""
+	initForce = false
+	backToTopLevel = "1"
+	policyPath = ""
+	language = ""
+	slugStyle = "github"
+}
+
+// widgetModule writes a Go module to a temporary directory, for init to
+// detect its name and description, and returns the directory.
+func widgetModule(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	writeTestFiles(t, map[string]string{
+		filepath.Join(dir, "go.mod"): "module github.com/acme/widget/v2\n\ngo 1.25\n",
+		filepath.Join(dir, "doc.go"): "// Package widget renders widgets.\npackage widget\n",
+	})
+	return dir
+}
+
+// checkScaffolded fails the test unless the README at path passes both
+// analyze --check and generate --check with the extra args.
+func checkScaffolded(t *testing.T, path string, args ...string) {
+	t.Helper()
+	setupAnalyzeTest()
+	RootCmd.SetArgs(append([]string{"analyze", "--check", "--file", path}, args...))
+	if err := RootCmd.Execute(); err != nil {
+		t.Errorf("analyze --check %s: %v", filepath.Base(path), err)
+	}
+	setupGenerateTest()
+	RootCmd.SetArgs(append([]string{"generate", "--check", "--file", path}, args...))
+	if err := RootCmd.Execute(); err != nil {
+		t.Errorf("generate --check %s: %v", filepath.Base(path), err)
+	}
+}
+
+func TestInitCommand(t *testing.T) {
+	dir := widgetModule(t)
+	readme := filepath.Join(dir, "README.md")
+	translation := filepath.Join(dir, "README_es.md")
+
+	setupInitTest()
+	RootCmd.SetArgs([]string{"init", "--file", readme, "--translations", "es"})
+	if err := RootCmd.Execute(); err != nil {
+		t.Fatalf("init failed: %v", err)
+	}
+
+	want := map[string][]string{
+		readme: {
+			"<!-- BEGIN_DOCS -->", `<a name="readme-top"></a>`, "# widget\n", "Package widget renders widgets.",
+			"[English](README.md) | [Español](README_es.md)", "1\\.1. [Installation](#installation)", "## License", "<!-- END_DOCS -->",
+		},
+		translation: {"**Tabla de contenidos**", "## Instalación", "volver arriba"},
+	}
+	for path, snippets := range want {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("failed to read %s: %v", path, err)
+		}
+		for _, s := range snippets {
+			if !strings.Contains(string(content), s) {
+				t.Errorf("%s: missing %q in:\n%s", filepath.Base(path), s, content)
+			}
+		}
+		checkScaffolded(t, path)
+	}
+}
+
+func TestInitCommandForce(t *testing.T) {
+	readme := filepath.Join(widgetModule(t), "README.md")
+
+	setupInitTest()
+	RootCmd.SetArgs([]string{"init", "--file", readme})
+	if err := RootCmd.Execute(); err != nil {
+		t.Fatalf("init failed: %v", err)
+	}
+	setupInitTest()
+	RootCmd.SetArgs([]string{"init", "--file", readme})
+	if err := RootCmd.Execute(); err == nil {
+		t.Error("init should refuse to replace an existing README")
+	}
+	setupInitTest()
+	RootCmd.SetArgs([]string{"init", "--file", readme, "--template", "minimal", "--name", "Widget", "--force"})
+	if err := RootCmd.Execute(); err != nil {
+		t.Fatalf("init --force failed: %v", err)
+	}
+	if content, _ := os.ReadFile(readme); !strings.Contains(string(content), "# Widget\n") || strings.Contains(string(content), "## Installation") {
+		t.Errorf("init --force did not rewrite the README from the minimal template:\n%s", content)
+	}
+}
+
+func TestInitCommandTOCFlags(t *testing.T) {
+	readme := filepath.Join(widgetModule(t), "README.md")
+	args := []string{"--depth", "1", "--number-headings"}
+
+	setupInitTest()
+	RootCmd.SetArgs(append([]string{"init", "--file", readme}, args...))
+	if err := RootCmd.Execute(); err != nil {
+		t.Fatalf("init failed: %v", err)
+	}
+	content, err := os.ReadFile(readme)
+	if err != nil {
+		t.Fatalf("failed to read README: %v", err)
+	}
+	if !strings.Contains(string(content), "[1. widget](#1-widget)") || strings.Contains(string(content), "(#installation)") {
+		t.Errorf("init %v should number the title and list only it in the TOC:\n%s", args, content)
+	}
+	checkScaffolded(t, readme, args...)
+}
+
+func TestInitCommandLanguage(t *testing.T) {
+	readme := filepath.Join(t.TempDir(), "README.md")
+
+	setupInitTest()
+	RootCmd.SetArgs([]string{"init", "--file", readme, "--lang", "xx"})
+	if err := RootCmd.Execute(); err == nil {
+		t.Error("init should reject an unsupported language")
+	}
+
+	setupInitTest()
+	RootCmd.SetArgs([]string{"init", "--file", readme, "--lang", "de", "--back-to-top-level", "2"})
+	if err := RootCmd.Execute(); err != nil {
+		t.Fatalf("init --lang de failed: %v", err)
+	}
+	content, _ := os.ReadFile(readme)
+	for _, s := range []string{"---\nlang: de\n---\n", "## Verwendung", "nach oben"} {
+		if !strings.Contains(string(content), s) {
+			t.Errorf("missing %q in:\n%s", s, content)
+		}
+	}
+	// One link closes the table of contents, and one each of the five
+	// sections of the standard template.
+	if n := strings.Count(string(content), "(<a href=\"#readme-top\">nach oben</a>)"); n != 6 {
+		t.Errorf("got %d back-to-top links at level 2, want 6:\n%s", n, content)
+	}
+}
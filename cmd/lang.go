@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/lpsm-dev/gtoc/internal/i18n"
+	"github.com/lpsm-dev/gtoc/internal/logger"
+	"github.com/spf13/cobra"
+)
+
+// language is the --lang flag value shared by the subcommands that write
+// localized text.
+var language string
+
+// addLangFlag registers the --lang flag.
+func addLangFlag(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&language, "lang", "", "Language of generated text such as the back-to-top link (e.g. en, pt-BR, es); detected from front matter or the file name suffix when unset")
+}
+
+// messageCatalog returns the built-in message catalog with the overrides
+// from the project configuration applied, and the configured default
+// language. An unknown --lang is an error.
+func messageCatalog() (*i18n.Catalog, string, error) {
+	cfg, err := loadConfig()
+	if err != nil {
+		return nil, "", err
+	}
+
+	catalog := i18n.NewCatalog(cfg.Messages)
+	if language != "" {
+		if _, ok := catalog.Lookup(language); !ok {
+			return nil, "", fmt.Errorf("unsupported language %q (available: %s)", language, strings.Join(catalog.Languages(), ", "))
+		}
+	}
+	return catalog, cfg.Lang, nil
+}
+
+// documentMessages returns the localized strings for the document at path
+// with the given content.
+func documentMessages(path, content string) (i18n.Messages, error) {
+	catalog, fallback, err := messageCatalog()
+	if err != nil {
+		return i18n.Messages{}, err
+	}
+
+	lang := catalog.Resolve(language, fallback, path, content)
+	logger.Debug("Resolved document language", "path", path, "lang", lang)
+	return catalog.Messages(lang), nil
+}
@@ -0,0 +1,131 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/lpsm-dev/gtoc/internal/fsutil"
+	"github.com/lpsm-dev/gtoc/internal/i18n"
+	"github.com/lpsm-dev/gtoc/internal/logger"
+)
+
+// Markers delimiting the language bar analyze maintains in the header.
+const (
+	languageBarStart = "<!-- START_LANGUAGE_BAR -->"
+	languageBarEnd   = "<!-- END_LANGUAGE_BAR -->"
+)
+
+// findTranslations returns the markdown files in absFilePath's directory
+// that are translations of it: files with the same name apart from a
+// language suffix, such as README.md, README_en.md and README.pt-BR.md. The
+// result includes absFilePath and lists the file without a suffix first,
+// then the others by name.
+func findTranslations(catalog *i18n.Catalog, absFilePath string) ([]string, error) {
+	dir := filepath.Dir(absFilePath)
+	stem, _ := catalog.SplitFileName(absFilePath)
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list translations: %w", err)
+	}
+
+	var paths []string
+	for _, e := range entries {
+		if e.IsDir() || !isMarkdownFile(e.Name()) {
+			continue
+		}
+		if s, _ := catalog.SplitFileName(e.Name()); s == stem {
+			paths = append(paths, filepath.Join(dir, e.Name()))
+		}
+	}
+
+	sort.SliceStable(paths, func(i, j int) bool {
+		return catalog.FileNameLanguage(paths[i]) == "" && catalog.FileNameLanguage(paths[j]) != ""
+	})
+	return paths, nil
+}
+
+// languageBar returns the links between the translations of the document at
+// absFilePath, whose language is lang, such as
+// "[Português](README.md) | [English](README_en.md)". It returns "" when
+// the document has no translations.
+func languageBar(catalog *i18n.Catalog, fallback, absFilePath, lang string) (string, error) {
+	paths, err := findTranslations(catalog, absFilePath)
+	if err != nil {
+		return "", err
+	}
+	if len(paths) < 2 {
+		return "", nil
+	}
+	logger.Debug("Found translations", "count", len(paths))
+
+	links := make([]string, len(paths))
+	for i, path := range paths {
+		pathLang := lang
+		if path != absFilePath {
+			content, err := fsutil.ReadText(path)
+			if err != nil {
+				return "", fmt.Errorf("failed to read translation: %w", err)
+			}
+			pathLang = catalog.Resolve("", fallback, path, content)
+		}
+
+		name := catalog.Messages(pathLang).LanguageName
+		if name == "" {
+			name = pathLang
+		}
+		links[i] = fmt.Sprintf("[%s](%s)", name, filepath.Base(path))
+	}
+	return strings.Join(links, " | "), nil
+}
+
+// updateLanguageBar replaces the marker-delimited language bar in content
+// with bar, removing it when bar is empty. A document without one gets it
+// inserted right after the BEGIN_DOCS header.
+func updateLanguageBar(content, bar string) string {
+	block := ""
+	if bar != "" {
+		block = languageBarStart + "\n" + bar + "\n" + languageBarEnd
+	}
+
+	start := strings.Index(content, languageBarStart)
+	end := strings.Index(content, languageBarEnd)
+	if start >= 0 && end > start {
+		rest := content[end+len(languageBarEnd):]
+		if block == "" {
+			rest = strings.TrimPrefix(strings.TrimPrefix(rest, "\n"), "\n")
+		}
+		return content[:start] + block + rest
+	}
+	if block == "" {
+		return content
+	}
+
+	at := headerEnd(content)
+	rest := content[at:]
+	block = "\n" + block + "\n"
+	if !strings.HasPrefix(rest, "\n") {
+		block += "\n"
+	}
+	return content[:at] + block + rest
+}
+
+// headerEnd returns the offset just past the BEGIN_DOCS line and the
+// readme-top anchor line that directly follows it, if any.
+func headerEnd(content string) int {
+	at := strings.Index(content, beginDocsMarker)
+	if at < 0 {
+		return 0
+	}
+	at += len(beginDocsMarker)
+	if strings.HasPrefix(content[at:], "\n") {
+		at++
+	}
+	if strings.HasPrefix(content[at:], readmeAnchor+"\n") {
+		at += len(readmeAnchor) + 1
+	}
+	return at
+}
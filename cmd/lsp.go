@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/lpsm-dev/gtoc/internal/generator"
+	"github.com/lpsm-dev/gtoc/internal/i18n"
+	"github.com/lpsm-dev/gtoc/internal/logger"
+	"github.com/lpsm-dev/gtoc/internal/lsp"
+	"github.com/spf13/cobra"
+)
+
+// lspCmd runs gtoc as a Language Server Protocol server over stdio.
+var lspCmd = &cobra.Command{
+	Use:   "lsp",
+	Short: "Run a Language Server Protocol server over stdio",
+	Long: `Run gtoc as a Language Server Protocol server speaking JSON-RPC on
+stdin and stdout, so editors such as VS Code, Neovim and Helix can use it
+without a dedicated extension. The server provides:
+- document symbols for every heading
+- an "Update table of contents" code action
+- diagnostics for a stale TOC and links to missing anchors
+- go-to-definition on #anchor links
+
+Tables of contents are generated the way gtoc generate would with the same
+--depth, --exclude, numbering and other TOC flags, so pass the ones the
+documents were generated with.
+
+Logs are written to stderr, so stdout carries only protocol messages.`,
+	Args: cobra.NoArgs,
+	RunE: runLSP,
+}
+
+// runLSP serves LSP requests on stdin/stdout until the client exits.
+func runLSP(cmd *cobra.Command, args []string) error {
+	catalog, fallback, err := messageCatalog()
+	if err != nil {
+		return err
+	}
+	slug, err := slugger()
+	if err != nil {
+		return err
+	}
+	opts, err := tocOptions()
+	if err != nil {
+		return err
+	}
+
+	logger.Info("Starting language server", "depth", depth)
+	server := lsp.NewServer(os.Stdin, os.Stdout, lsp.Options{
+		Generator: func(messages i18n.Messages) *generator.Generator {
+			return newTOCGenerator("", opts, messages)
+		},
+		NumberHeadings:  numberHeadings,
+		Language:        language,
+		DefaultLanguage: fallback,
+		Catalog:         catalog,
+		Slugger:         slug,
+	})
+	return server.Run()
+}
+
+func init() {
+	addTOCFlags(lspCmd)
+	addLangFlag(lspCmd)
+	addSlugFlag(lspCmd)
+}
@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/lpsm-dev/gtoc/internal/generator"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// Flags selecting how outline numbers are written.
+	numberFormat    string
+	numberSeparator string
+	numberPrefix    string
+	numberFromLevel int
+	numberDepth     int
+)
+
+// addNumberingFlags registers the flags controlling the numbering scheme.
+func addNumberingFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&numberFormat, "number-format", "", "Comma-separated number format per level: 1, I, i, A or a (e.g. I,1,a); the last one repeats")
+	cmd.Flags().StringVar(&numberSeparator, "number-separator", "", "Separator between the numbers of each level (default \".\")")
+	cmd.Flags().StringVar(&numberPrefix, "number-prefix", "", "Text written before every outline number (e.g. §)")
+	cmd.Flags().IntVar(&numberFromLevel, "number-from-level", 0, "Heading level numbering starts at; shallower headings stay unnumbered (0 for the shallowest present)")
+	cmd.Flags().IntVar(&numberDepth, "number-depth", 0, "Deepest heading level that is numbered, independent of --depth (0 for unlimited)")
+}
+
+// numberingScheme returns the numbering scheme selected by the numbering
+// flags.
+func numberingScheme() (generator.NumberingScheme, error) {
+	formats, err := generator.ParseNumberFormats(numberFormat)
+	if err != nil {
+		return generator.NumberingScheme{}, err
+	}
+	if numberFromLevel < 0 || numberDepth < 0 {
+		return generator.NumberingScheme{}, fmt.Errorf("--number-from-level and --number-depth must not be negative")
+	}
+
+	return generator.NumberingScheme{
+		Formats:   formats,
+		Separator: numberSeparator,
+		Prefix:    numberPrefix,
+		FromLevel: numberFromLevel,
+		Depth:     numberDepth,
+	}, nil
+}
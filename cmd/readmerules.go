@@ -0,0 +1,159 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/lpsm-dev/gtoc/internal/lint"
+)
+
+// IDs of the rules about the README layout analyze maintains.
+const (
+	ruleDocsMarkers = "docs-markers"
+	ruleLanguageBar = "language-bar"
+	ruleBackToTop   = "back-to-top"
+)
+
+// readmeRules returns the README layout rules for a document whose
+// back-to-top link is link, added to the sections at levels, and whose
+// language bar is bar ("" when it has no translations). Their fixes run in
+// this order, so the language bar is placed under the header the first rule
+// adds.
+func readmeRules(link, bar string, levels levelRange) []lint.Rule {
+	return []lint.Rule{
+		{
+			ID:          ruleDocsMarkers,
+			Description: "The README has the BEGIN_DOCS header with the readme-top anchor and ends with END_DOCS",
+			Severity:    lint.SeverityError,
+			Check:       checkDocsMarkers,
+			Fix:         fixDocsMarkers,
+		},
+		{
+			ID:          ruleLanguageBar,
+			Description: "The header links the README's translations",
+			Severity:    lint.SeverityError,
+			Check: func(doc *lint.Document) []lint.Finding {
+				return checkLanguageBar(doc, bar)
+			},
+			Fix: func(doc *lint.Document) string {
+				return updateLanguageBar(doc.Content, bar)
+			},
+		},
+		{
+			ID:          ruleBackToTop,
+			Description: "Every top-level section, or section at --back-to-top-level, ends with a back-to-top link in the document's language",
+			Severity:    lint.SeverityError,
+			Check: func(doc *lint.Document) []lint.Finding {
+				return checkBackToTop(doc.Content, link, levels)
+			},
+			Fix: func(doc *lint.Document) string {
+				return addBackToTopLinks(doc.Content, link, levels)
+			},
+		},
+	}
+}
+
+// checkDocsMarkers reports a missing BEGIN_DOCS header or END_DOCS marker.
+func checkDocsMarkers(doc *lint.Document) []lint.Finding {
+	var findings []lint.Finding
+	if !strings.Contains(doc.Content, beginDocsMarker) {
+		findings = append(findings, lint.Finding{
+			Line:       1,
+			Message:    "the " + beginDocsMarker + " header is missing",
+			Suggestion: "add " + beginDocsMarker + " and " + readmeAnchor + " at the top",
+		})
+	}
+	if !strings.Contains(doc.Content, endDocsMarker) {
+		findings = append(findings, lint.Finding{
+			Line:       len(doc.Lines),
+			Message:    "the " + endDocsMarker + " marker is missing",
+			Suggestion: "add " + endDocsMarker + " at the end",
+		})
+	}
+	return findings
+}
+
+// fixDocsMarkers adds the header after any front matter and the END_DOCS
+// marker at the end, where missing.
+func fixDocsMarkers(doc *lint.Document) string {
+	content := doc.Content
+	if !strings.Contains(content, beginDocsMarker) {
+		at := frontMatterEnd(content)
+		content = content[:at] + beginDocsMarker + "\n" + readmeAnchor + "\n\n" + content[at:]
+	}
+	return addEndDocsMarker(content)
+}
+
+// checkLanguageBar reports a language bar that is missing, stale, or left
+// over after the translations were removed.
+func checkLanguageBar(doc *lint.Document, bar string) []lint.Finding {
+	if updateLanguageBar(doc.Content, bar) == doc.Content {
+		return nil
+	}
+	line := lineOf(doc.Content, strings.Index(doc.Content, languageBarStart))
+	if bar == "" {
+		return []lint.Finding{{
+			Line:       line,
+			Message:    "the language bar links translations that no longer exist",
+			Suggestion: "remove the language bar block",
+		}}
+	}
+	return []lint.Finding{{
+		Line:       line,
+		Message:    "the language bar is missing or out of date",
+		Suggestion: "link the translations in the header: " + bar,
+	}}
+}
+
+// checkBackToTop reports every section at levels before END_DOCS whose
+// back-to-top link is missing or in another language.
+func checkBackToTop(content, link string, levels levelRange) []lint.Finding {
+	body, _ := splitAtEndDocsMarker(content)
+	starts := findHeadingLineStarts(body, levels.max)
+
+	var findings []lint.Finding
+	for i, start := range starts {
+		if start.level < levels.min {
+			continue
+		}
+		section := body[start.offset:sectionEnd(body, starts, i)]
+		if processSection(section, link) != section {
+			findings = append(findings, lint.Finding{
+				Line:       lineOf(body, start.offset),
+				Message:    "the section does not end with a back-to-top link in the document's language",
+				Suggestion: "end the section with " + link,
+			})
+		}
+	}
+	return findings
+}
+
+// lineOf returns the 1-based line of the byte offset in content, or 1 for a
+// negative offset.
+func lineOf(content string, offset int) int {
+	if offset < 0 {
+		return 1
+	}
+	return strings.Count(content[:offset], "\n") + 1
+}
+
+// levelRange is a range of heading levels, from min to max inclusive.
+type levelRange struct {
+	min, max int
+}
+
+// parseLevelRange parses a heading level such as "2" or a range such as
+// "1-2".
+func parseLevelRange(s string) (levelRange, error) {
+	from, to, isRange := strings.Cut(strings.TrimSpace(s), "-")
+	if !isRange {
+		to = from
+	}
+	minLevel, errMin := strconv.Atoi(strings.TrimSpace(from))
+	maxLevel, errMax := strconv.Atoi(strings.TrimSpace(to))
+	if errMin != nil || errMax != nil || minLevel < 1 || maxLevel > 6 || minLevel > maxLevel {
+		return levelRange{}, fmt.Errorf("%q is not a heading level from 1 to 6 or a range such as 1-2", s)
+	}
+	return levelRange{min: minLevel, max: maxLevel}, nil
+}
@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"strings"
+
+	"github.com/lpsm-dev/gtoc/internal/generator"
+	"github.com/lpsm-dev/gtoc/internal/i18n"
+)
+
+// revertBestPractices removes from content everything analyze --fix adds:
+// the table of contents, the END_DOCS marker, the back-to-top links, the
+// language bar and the BEGIN_DOCS header with the readme-top anchor. Text
+// gtoc did not write is left as it was.
+func revertBestPractices(content string) string {
+	content = generator.RemoveTOC(content)
+	content = removeEndDocsMarker(content)
+	content = removeBackToTopLinks(content)
+	content = updateLanguageBar(content, "")
+	return removeDocsHeader(content)
+}
+
+// removeEndDocsMarker removes the END_DOCS marker line. When the marker ends
+// the file, the blank line the last back-to-top link left before it goes
+// too, so the file ends with a single newline again.
+func removeEndDocsMarker(content string) string {
+	if strings.HasSuffix(content, "\n"+endDocsMarker+"\n") {
+		return strings.TrimRight(strings.TrimSuffix(content, endDocsMarker+"\n"), "\n") + "\n"
+	}
+	return strings.Replace(content, endDocsMarker+"\n", "", 1)
+}
+
+// removeBackToTopLinks removes every line holding only a back-to-top link,
+// in any language, outside fenced code blocks, together with one of the
+// blank lines around it, which processSection added.
+func removeBackToTopLinks(content string) string {
+	lines := strings.Split(content, "\n")
+	removed := make([]bool, len(lines))
+	inFence, fenceMarker := false, ""
+	for i, line := range lines {
+		if marker := codeFenceRune(line); marker != "" {
+			inFence, fenceMarker = toggleFence(inFence, fenceMarker, marker)
+			continue
+		}
+		if inFence || !isBackToTopLine(line) {
+			continue
+		}
+		removed[i] = true
+		if blank := separatingBlankLine(lines, i); blank >= 0 {
+			removed[blank] = true
+		}
+	}
+
+	kept := lines[:0]
+	for i, line := range lines {
+		if !removed[i] {
+			kept = append(kept, line)
+		}
+	}
+	return strings.Join(kept, "\n")
+}
+
+// isBackToTopLine reports whether line is a back-to-top link and nothing
+// else.
+func isBackToTopLine(line string) bool {
+	loc := i18n.FindBackToTopLink(line)
+	return loc != nil && loc[0] == 0 && loc[1] == len(line)
+}
+
+// separatingBlankLine returns the index of the blank line to remove with the
+// link on line i: the one after it when it has blank lines on both sides,
+// or the one before it when it is the last line of the file. It returns -1
+// otherwise.
+func separatingBlankLine(lines []string, i int) int {
+	if i == 0 || lines[i-1] != "" {
+		return -1
+	}
+	last := len(lines) - 1
+	switch {
+	case i+1 < last && lines[i+1] == "":
+		return i + 1
+	case i+1 >= last:
+		return i - 1
+	default:
+		return -1
+	}
+}
+
+// removeDocsHeader removes the BEGIN_DOCS header and the readme-top anchor,
+// with the blank line fixDocsMarkers puts after them.
+func removeDocsHeader(content string) string {
+	header := beginDocsMarker + "\n" + readmeAnchor + "\n\n"
+	if strings.Contains(content, header) {
+		return strings.Replace(content, header, "", 1)
+	}
+	content = strings.Replace(content, beginDocsMarker+"\n", "", 1)
+	return strings.Replace(content, readmeAnchor+"\n", "", 1)
+}
@@ -0,0 +1,87 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestAnalyzeRevertRestoresOriginal(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{
+			name:    "single title",
+			content: "# Project\n\nIntro.\n\n## Install\n\nRun it.\n\n## Usage\n\nUse it.\n",
+		},
+		{
+			name:    "several sections",
+			content: "# One\n\nFirst.\n\n# Two\n\nSecond.\n\n## Nested\n\nMore.\n",
+		},
+		{
+			name:    "front matter",
+			content: "---\ntitle: Doc\n---\n# Title\n\nText.\n",
+		},
+		{
+			name:    "code fence with a back-to-top link",
+			content: "# Title\n\n```html\n<p align=\"right\">(<a href=\"#readme-top\">back to top</a>)</p>\n```\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			testFile := filepath.Join(dir, "README.md")
+			if err := os.WriteFile(testFile, []byte(tt.content), 0644); err != nil {
+				t.Fatalf("failed to create test file: %v", err)
+			}
+			if err := os.WriteFile(filepath.Join(dir, "README_en.md"), []byte("# Title\n"), 0644); err != nil {
+				t.Fatalf("failed to create translation: %v", err)
+			}
+
+			setupAnalyzeTest()
+			RootCmd.SetArgs([]string{"analyze", "--fix", "--file", testFile})
+			_ = RootCmd.Execute()
+			fixed, _ := os.ReadFile(testFile)
+			if string(fixed) == tt.content {
+				t.Fatal("analyze --fix should have changed the file")
+			}
+
+			setupAnalyzeTest()
+			RootCmd.SetArgs([]string{"analyze", "--revert", "--file", testFile})
+			if err := RootCmd.Execute(); err != nil {
+				t.Fatalf("analyze --revert failed: %v", err)
+			}
+			if reverted, _ := os.ReadFile(testFile); string(reverted) != tt.content {
+				t.Errorf("analyze --revert should restore the original\ngot:\n%q\nwant:\n%q\nfixed was:\n%s", reverted, tt.content, fixed)
+			}
+		})
+	}
+}
+
+func TestRevertBestPracticesKeepsForeignText(t *testing.T) {
+	content := "Intro\n<p align=\"right\">(<a href=\"#readme-top\">back to top</a>)</p> inline\n\n# Title\n\nText.\n"
+	if got := revertBestPractices(content); got != content {
+		t.Errorf("text gtoc did not write should stay, got:\n%q", got)
+	}
+}
+
+func TestAnalyzeRevertCheck(t *testing.T) {
+	testFile := filepath.Join(t.TempDir(), "README.md")
+	if err := os.WriteFile(testFile, []byte("# Title\n\nText.\n"), 0644); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+
+	setupAnalyzeTest()
+	RootCmd.SetArgs([]string{"analyze", "--revert", "--check", "--file", testFile})
+	if err := RootCmd.Execute(); err != nil {
+		t.Errorf("a file without gtoc markers has nothing to revert, got %v", err)
+	}
+
+	setupAnalyzeTest()
+	RootCmd.SetArgs([]string{"analyze", "--revert", "--fix", "--file", testFile})
+	if err := RootCmd.Execute(); err == nil {
+		t.Error("expected an error for --revert with --fix")
+	}
+}
@@ -82,11 +82,17 @@ func init() {
 	RootCmd.PersistentFlags().StringVar(&logLevel, "log-level", "warn", "Set log level (debug, info, warn, error, fatal)")
 	RootCmd.PersistentFlags().StringVar(&logFormat, "log-format", "text", "Log format (text, json)")
 	RootCmd.PersistentFlags().BoolVar(&logNoColors, "log-no-colors", false, "Disable colors in logs")
+	RootCmd.PersistentFlags().StringVar(&configPath, "config", "", "Path to the JSON config file (default .gtoc.json in the working directory, if present)")
 
 	// Register every subcommand here so command registration lives in a
 	// single, predictable place.
 	RootCmd.AddCommand(generateCmd)
 	RootCmd.AddCommand(analyzeCmd)
+	RootCmd.AddCommand(initCmd)
+	RootCmd.AddCommand(scoreCmd)
+	RootCmd.AddCommand(hooksCmd)
+	RootCmd.AddCommand(lspCmd)
+	RootCmd.AddCommand(syncCheckCmd)
 	RootCmd.AddCommand(versionCmd)
 	RootCmd.AddCommand(upgradeCmd)
 }
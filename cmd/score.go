@@ -0,0 +1,383 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/lpsm-dev/gtoc/internal/fsutil"
+	"github.com/lpsm-dev/gtoc/internal/generator"
+	"github.com/lpsm-dev/gtoc/internal/lint"
+	"github.com/lpsm-dev/gtoc/internal/logger"
+	"github.com/lpsm-dev/gtoc/internal/score"
+	"github.com/spf13/cobra"
+)
+
+var (
+	scoreFile   string
+	scoreMin    int
+	scoreFormat string
+)
+
+// errScoreBelowMinimum is returned when the score is below --min.
+var errScoreBelowMinimum = errors.New("README score is below the minimum")
+
+// Weights of the score categories, adding up to 100.
+const (
+	weightHeadings   = 25
+	weightTOC        = 15
+	weightBackToTop  = 10
+	weightAnchors    = 15
+	weightSections   = 15
+	weightCodeBlocks = 10
+	weightImages     = 10
+)
+
+// headingRules are the structure rules the heading structure category
+// counts.
+var headingRules = []string{
+	lint.RuleHeadingIncrement,
+	lint.RuleSingleH1,
+	lint.RuleDuplicateSibling,
+	lint.RuleEmptySection,
+	lint.RuleTrailingPunctuation,
+}
+
+// scoreCmd grades a README from 0 to 100.
+var scoreCmd = &cobra.Command{
+	Use:   "score [file]",
+	Short: "Grade a README from 0 to 100 with a per-category breakdown",
+	Long: `Score a README from 0 to 100 and print a report card with the points of
+each category:
+
+  Heading structure   25  headings that pass the structure rules
+  Table of contents   15  a table of contents that is present and up to date
+  Back-to-top links   10  sections ending with a back-to-top link
+  Anchor links        15  in-document links whose anchor exists
+  Required sections   15  sections the section policy requires
+  Code blocks         10  fenced code blocks with a language
+  Images              10  images with alt text
+
+Each category earns its share of checks that pass. A category with nothing
+to check, such as images in a README without any, earns its full weight.
+Required sections are skipped without a section policy, and the heading,
+back-to-top and required sections categories follow the rule severities in
+the config, skipping rules that are off; the score is scaled over the
+categories left.
+
+The table of contents is up to date when gtoc generate --check would pass
+with the same flags, so pass the --depth, --exclude, numbering and other
+TOC flags the README was generated with.
+
+--min fails the command when the score is lower, to gate documentation
+quality in CI, and --format json prints the report for tracking over time.
+
+Example:
+  gtoc score
+  gtoc score docs/README.md --min 80
+  gtoc score --format json > score.json
+  gtoc score --depth 2 --number-headings`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runScore,
+}
+
+// runScore scores the README and prints its report card, failing when the
+// score is below --min.
+func runScore(cmd *cobra.Command, args []string) error {
+	if len(args) > 0 {
+		scoreFile = args[0]
+	}
+	format, err := validateScoreFlags()
+	if err != nil {
+		return err
+	}
+
+	absFilePath, err := validateFileExists(scoreFile)
+	if err != nil {
+		return err
+	}
+	snap, err := fsutil.ReadSnapshot(absFilePath)
+	if err != nil {
+		return fmt.Errorf("failed to read file: %w", err)
+	}
+	doc, linter, err := prepareAnalysis(absFilePath, snap.Text)
+	if err != nil {
+		return err
+	}
+	categories, err := scoreCategories(doc, linter)
+	if err != nil {
+		return err
+	}
+
+	report := score.New(scoreFile, categories)
+	logger.Debug("Scored README", "path", absFilePath, "score", report.Score)
+	if err := writeScore(format, report); err != nil {
+		return err
+	}
+
+	if report.Score < scoreMin {
+		return fmt.Errorf("%w: %d < %d", errScoreBelowMinimum, report.Score, scoreMin)
+	}
+	return nil
+}
+
+// validateScoreFlags checks the score flags and returns the report format.
+func validateScoreFlags() (lint.Format, error) {
+	format, err := lint.ParseFormat(scoreFormat)
+	if err != nil || (format != lint.FormatText && format != lint.FormatJSON) {
+		return "", fmt.Errorf("unknown format %q (use text or json)", scoreFormat)
+	}
+	if scoreMin < 0 || scoreMin > 100 {
+		return "", fmt.Errorf("invalid --min %d (use 0 to 100)", scoreMin)
+	}
+	return format, nil
+}
+
+// writeScore prints report to standard output in format.
+func writeScore(format lint.Format, report score.Report) error {
+	var err error
+	if format == lint.FormatJSON {
+		err = score.WriteJSON(os.Stdout, report)
+	} else {
+		err = score.WriteText(os.Stdout, report)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write report: %w", err)
+	}
+	return nil
+}
+
+// scoreCategories scores every category of doc, using the findings of
+// linter where a category follows a rule.
+func scoreCategories(doc *lint.Document, linter *lint.Linter) ([]score.Category, error) {
+	findings := map[string][]lint.Finding{}
+	for _, f := range linter.Check(doc) {
+		findings[f.Rule] = append(findings[f.Rule], f)
+	}
+	enabled := map[string]bool{}
+	for _, r := range linter.Rules() {
+		enabled[r.ID] = r.Severity != lint.SeverityOff
+	}
+
+	levels, err := parseLevelRange(backToTopLevel)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --back-to-top-level: %w", err)
+	}
+	sections, err := sectionsCategory(doc, findings, enabled)
+	if err != nil {
+		return nil, err
+	}
+	return []score.Category{
+		headingsCategory(doc, findings, enabled),
+		tocCategory(doc),
+		backToTopCategory(doc, levels, findings, enabled),
+		anchorsCategory(doc),
+		sections,
+		codeBlocksCategory(doc),
+		imagesCategory(doc),
+	}, nil
+}
+
+// headingsCategory scores the headings that no enabled structure rule
+// reports.
+func headingsCategory(doc *lint.Document, findings map[string][]lint.Finding, enabled map[string]bool) score.Category {
+	c := score.Category{ID: "headings", Name: "Heading structure", Weight: weightHeadings, Total: len(doc.Headings)}
+	if c.Total == 0 {
+		c.Total = 1
+		c.Detail = "no headings"
+		return c
+	}
+
+	failing := map[int]bool{}
+	var rules []string
+	for _, id := range headingRules {
+		if !enabled[id] || len(findings[id]) == 0 {
+			continue
+		}
+		rules = append(rules, id)
+		for _, f := range findings[id] {
+			failing[f.Line] = true
+		}
+	}
+	c.Passed = c.Total - len(failing)
+	c.Detail = fmt.Sprintf("%d of %d headings pass the structure rules", c.Passed, c.Total)
+	if len(rules) > 0 {
+		c.Detail += " (failing: " + strings.Join(rules, ", ") + ")"
+	}
+	return c
+}
+
+// tocCategory scores whether doc has a table of contents, and whether it is
+// up to date with what generate would write given the same TOC flags.
+func tocCategory(doc *lint.Document) score.Category {
+	c := score.Category{ID: "toc", Name: "Table of contents", Weight: weightTOC, Total: 2}
+	switch {
+	case !generator.HasTOC(doc.Content):
+		c.Detail = "missing; run gtoc generate to add one"
+	case doc.RefreshTOC() != doc:
+		c.Passed = 1
+		c.Detail = "out of date; run gtoc generate to update it"
+	default:
+		c.Passed = 2
+		c.Detail = "present and up to date"
+	}
+	return c
+}
+
+// backToTopCategory scores the sections at levels that end with a
+// back-to-top link.
+func backToTopCategory(doc *lint.Document, levels levelRange, findings map[string][]lint.Finding, enabled map[string]bool) score.Category {
+	c := score.Category{ID: "back-to-top", Name: "Back-to-top links", Weight: weightBackToTop}
+	if !enabled[ruleBackToTop] {
+		c.Skipped = true
+		c.Detail = "the " + ruleBackToTop + " rule is off"
+		return c
+	}
+
+	body, _ := splitAtEndDocsMarker(doc.Content)
+	for _, start := range findHeadingLineStarts(body, levels.max) {
+		if start.level >= levels.min {
+			c.Total++
+		}
+	}
+	if c.Total == 0 {
+		c.Detail = "no sections at --back-to-top-level " + backToTopLevel
+		return c
+	}
+	c.Passed = max(c.Total-len(findings[ruleBackToTop]), 0)
+	c.Detail = fmt.Sprintf("%d of %d sections end with a back-to-top link", c.Passed, c.Total)
+	return c
+}
+
+// anchorsCategory scores the in-document links whose anchor exists.
+func anchorsCategory(doc *lint.Document) score.Category {
+	links, broken := doc.AnchorLinks()
+	c := score.Category{ID: "anchors", Name: "Anchor links", Weight: weightAnchors, Total: len(links), Passed: len(links) - len(broken)}
+	switch {
+	case c.Total == 0:
+		c.Detail = "no anchor links"
+		return c
+	case len(broken) == 0:
+		c.Detail = fmt.Sprintf("all %d anchor links resolve", c.Total)
+		return c
+	}
+
+	anchors := make([]string, len(broken))
+	for i, link := range broken {
+		anchors[i] = fmt.Sprintf("#%s (line %d)", link.Anchor, link.Line)
+	}
+	c.Detail = fmt.Sprintf("%d of %d anchor links are broken: %s", len(broken), c.Total, strings.Join(anchors, ", "))
+	return c
+}
+
+// sectionsCategory scores the sections the section policy requires. It is
+// skipped without a policy requiring any.
+func sectionsCategory(doc *lint.Document, findings map[string][]lint.Finding, enabled map[string]bool) (score.Category, error) {
+	c := score.Category{ID: "sections", Name: "Required sections", Weight: weightSections, Skipped: true}
+	if !enabled[ruleRequiredSections] {
+		c.Detail = "the " + ruleRequiredSections + " rule is off"
+		return c, nil
+	}
+	cfg, err := loadConfig()
+	if err != nil {
+		return c, err
+	}
+	policy, err := sectionPolicy(cfg)
+	if err != nil {
+		return c, err
+	}
+	if policy != nil {
+		for _, s := range policy.List {
+			if s.Required {
+				c.Total++
+			}
+		}
+	}
+	if c.Total == 0 {
+		c.Detail = "no section policy requires sections; set one with --policy"
+		return c, nil
+	}
+
+	c.Skipped = false
+	missing := missingSections(policy, matchSections(doc, policy, sectionLevel(doc, policy)))
+	c.Passed = c.Total - len(missing)
+	if len(missing) == 0 {
+		c.Detail = fmt.Sprintf("all %d required sections are present", c.Total)
+		return c, nil
+	}
+	names := make([]string, len(missing))
+	for i, spec := range missing {
+		names[i] = policy.List[spec].Name
+	}
+	c.Detail = "missing: " + strings.Join(names, ", ")
+	return c, nil
+}
+
+// codeBlocksCategory scores the fenced code blocks that name a language.
+func codeBlocksCategory(doc *lint.Document) score.Category {
+	c := score.Category{ID: "code-blocks", Name: "Code blocks", Weight: weightCodeBlocks}
+	var untagged []int
+	for _, b := range doc.CodeBlocks() {
+		c.Total++
+		if b.Language() == "" {
+			untagged = append(untagged, b.Line)
+		}
+	}
+	if c.Total == 0 {
+		c.Detail = "no code blocks"
+		return c
+	}
+	c.Passed = c.Total - len(untagged)
+	c.Detail = fmt.Sprintf("%d of %d code blocks name a language", c.Passed, c.Total)
+	if len(untagged) > 0 {
+		c.Detail += " (without one: " + lineList(untagged) + ")"
+	}
+	return c
+}
+
+// imagesCategory scores the images with alt text.
+func imagesCategory(doc *lint.Document) score.Category {
+	c := score.Category{ID: "images", Name: "Images", Weight: weightImages}
+	var noAlt []int
+	for _, img := range doc.Images() {
+		c.Total++
+		if img.Alt == "" {
+			noAlt = append(noAlt, img.Line)
+		}
+	}
+	if c.Total == 0 {
+		c.Detail = "no images"
+		return c
+	}
+	c.Passed = c.Total - len(noAlt)
+	c.Detail = fmt.Sprintf("%d of %d images have alt text", c.Passed, c.Total)
+	if len(noAlt) > 0 {
+		c.Detail += " (without it: " + lineList(noAlt) + ")"
+	}
+	return c
+}
+
+// lineList formats 1-based line numbers as "line 3" or "lines 3, 9".
+func lineList(lines []int) string {
+	parts := make([]string, len(lines))
+	for i, n := range lines {
+		parts[i] = strconv.Itoa(n)
+	}
+	if len(lines) == 1 {
+		return "line " + parts[0]
+	}
+	return "lines " + strings.Join(parts, ", ")
+}
+
+func init() {
+	scoreCmd.Flags().StringVar(&scoreFile, "file", "README.md", "Path to the README to score (or pass it as a positional argument)")
+	scoreCmd.Flags().IntVar(&scoreMin, "min", 0, "Exit with a non-zero status when the score is below this minimum (0-100)")
+	scoreCmd.Flags().StringVar(&scoreFormat, "format", string(lint.FormatText), "Output format of the report: text or json")
+	scoreCmd.Flags().StringVar(&backToTopLevel, "back-to-top-level", "1", "Heading level, or range of levels such as 1-2, whose sections should end with a back-to-top link")
+	scoreCmd.Flags().StringVar(&policyPath, "policy", "", "Section policy file listing the required sections, overriding the config's \"sections\"")
+	addTOCFlags(scoreCmd)
+	addLangFlag(scoreCmd)
+	addSlugFlag(scoreCmd)
+}
@@ -0,0 +1,140 @@
+package cmd
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// setupScoreTest resets the root command, the score flags and the TOC flags
+// score shares with generate between runs.
+func setupScoreTest() {
+	resetRootCmd()
+	resetTOCFlags()
+	scoreFile = "README.md"
+	scoreMin = 0
+	scoreFormat = "text"
+	backToTopLevel = "1"
+	policyPath = ""
+	language = ""
+	slugStyle = "github"
+}
+
+func TestScoreCategories(t *testing.T) {
+	setupScoreTest()
+	testFile := filepath.Join(t.TempDir(), "README.md")
+	content := "# Title\n\nSee [usage](#usage) and [nowhere](#nowhere).\n\n## Usage:\n\n```\nrun\n```\n\n```sh\nmake\n```\n\n![](shot.png) ![Logo](logo.png)\n"
+	doc, linter, err := prepareAnalysis(testFile, content)
+	if err != nil {
+		t.Fatalf("prepareAnalysis failed: %v", err)
+	}
+	categories, err := scoreCategories(doc, linter)
+	if err != nil {
+		t.Fatalf("scoreCategories failed: %v", err)
+	}
+
+	want := map[string]struct {
+		passed, total int
+		skipped       bool
+	}{
+		"headings":    {1, 2, false},
+		"toc":         {0, 2, false},
+		"back-to-top": {0, 1, false},
+		"anchors":     {1, 2, false},
+		"sections":    {0, 0, true},
+		"code-blocks": {1, 2, false},
+		"images":      {1, 2, false},
+	}
+	if len(categories) != len(want) {
+		t.Fatalf("got %d categories, want %d", len(categories), len(want))
+	}
+	for _, c := range categories {
+		w := want[c.ID]
+		if c.Passed != w.passed || c.Total != w.total || c.Skipped != w.skipped {
+			t.Errorf("%s: got %d/%d skipped=%v, want %d/%d skipped=%v (%s)", c.ID, c.Passed, c.Total, c.Skipped, w.passed, w.total, w.skipped, c.Detail)
+		}
+	}
+}
+
+func TestScoreCommand(t *testing.T) {
+	dir := t.TempDir()
+	testFile := filepath.Join(dir, "README.md")
+	if err := os.WriteFile(testFile, []byte("# Title\n\nText.\n\n## Usage\n\nMore.\n"), 0644); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+	policy := filepath.Join(dir, "policy.json")
+	if err := os.WriteFile(policy, []byte(`{"list": [{"name": "Usage", "required": true}, {"name": "License", "required": true}]}`), 0644); err != nil {
+		t.Fatalf("failed to create policy: %v", err)
+	}
+
+	setupScoreTest()
+	RootCmd.SetArgs([]string{"score", testFile, "--policy", policy})
+	if err := RootCmd.Execute(); err != nil {
+		t.Fatalf("score failed: %v", err)
+	}
+
+	setupScoreTest()
+	RootCmd.SetArgs([]string{"score", testFile, "--min", "95"})
+	if err := RootCmd.Execute(); !errors.Is(err, errScoreBelowMinimum) {
+		t.Errorf("score --min 95 should fail on a README without a TOC, got %v", err)
+	}
+
+	setupInitTest()
+	RootCmd.SetArgs([]string{"init", "--file", testFile, "--force"})
+	if err := RootCmd.Execute(); err != nil {
+		t.Fatalf("init failed: %v", err)
+	}
+	setupScoreTest()
+	RootCmd.SetArgs([]string{"score", "--file", testFile, "--min", "100", "--format", "json"})
+	if err := RootCmd.Execute(); err != nil {
+		t.Errorf("a README scaffolded by init should score 100, got %v", err)
+	}
+
+	for _, args := range [][]string{{"--format", "sarif"}, {"--min", "101"}} {
+		setupScoreTest()
+		RootCmd.SetArgs(append([]string{"score", testFile}, args...))
+		if err := RootCmd.Execute(); err == nil {
+			t.Errorf("score %v should fail", args)
+		}
+	}
+}
+
+func TestScoreTOCUsesGenerateFlags(t *testing.T) {
+	testFile := filepath.Join(t.TempDir(), "README.md")
+	content := "# Title\n\n## Usage\n\n### Flags\n\n## License\n"
+	if err := os.WriteFile(testFile, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+	setupGenerateTest()
+	RootCmd.SetArgs([]string{"generate", "--file", testFile, "--depth", "2", "--number-headings"})
+	if err := RootCmd.Execute(); err != nil {
+		t.Fatalf("generate failed: %v", err)
+	}
+
+	tests := []struct {
+		args   []string
+		passed int
+	}{
+		{nil, 1},
+		{[]string{"--depth", "2"}, 1},
+		{[]string{"--depth", "2", "--number-headings"}, 2},
+	}
+	for _, tt := range tests {
+		setupScoreTest()
+		if err := scoreCmd.ParseFlags(tt.args); err != nil {
+			t.Fatalf("failed to parse %v: %v", tt.args, err)
+		}
+		updated, err := os.ReadFile(testFile)
+		if err != nil {
+			t.Fatalf("failed to read test file: %v", err)
+		}
+		doc, _, err := prepareAnalysis(testFile, string(updated))
+		if err != nil {
+			t.Fatalf("prepareAnalysis failed: %v", err)
+		}
+		if c := tocCategory(doc); c.Passed != tt.passed {
+			t.Errorf("score %v: got toc %d/2 (%s), want %d/2", tt.args, c.Passed, c.Detail, tt.passed)
+		}
+	}
+}
@@ -0,0 +1,260 @@
+package cmd
+
+import (
+	"cmp"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/lpsm-dev/gtoc/internal/config"
+	"github.com/lpsm-dev/gtoc/internal/generator"
+	"github.com/lpsm-dev/gtoc/internal/lint"
+)
+
+// IDs of the rules about the sections required by the section policy.
+const (
+	ruleRequiredSections = "required-sections"
+	ruleSectionOrder     = "section-order"
+)
+
+// defaultPlaceholder is the content of scaffolded sections when the policy
+// does not set one.
+const defaultPlaceholder = "TODO"
+
+// sectionRules returns the rules checking a document against policy, which
+// may be nil when no policy is configured. Missing sections are only
+// scaffolded when the policy asks for it.
+func sectionRules(policy *config.SectionPolicy) []lint.Rule {
+	required := lint.Rule{
+		ID:          ruleRequiredSections,
+		Description: "The README has every section the section policy requires",
+		Severity:    lint.SeverityError,
+		Check: func(doc *lint.Document) []lint.Finding {
+			return checkRequiredSections(doc, policy)
+		},
+	}
+	if policy != nil && policy.Scaffold {
+		required.Fix = func(doc *lint.Document) string {
+			return scaffoldSections(doc, policy)
+		}
+	}
+
+	return []lint.Rule{
+		required,
+		{
+			ID:          ruleSectionOrder,
+			Description: "The README's sections follow the order of the section policy",
+			Severity:    lint.SeverityWarning,
+			Check: func(doc *lint.Document) []lint.Finding {
+				return checkSectionOrder(doc, policy)
+			},
+		},
+	}
+}
+
+// sectionMatch is a heading matching the policy section at index spec.
+type sectionMatch struct {
+	spec    int
+	heading *generator.Heading
+}
+
+// sectionLevel returns the heading level of the policy's sections in doc.
+func sectionLevel(doc *lint.Document, policy *config.SectionPolicy) int {
+	if policy.Level > 0 {
+		return policy.Level
+	}
+	h1 := 0
+	for _, h := range doc.Headings {
+		if h.Level == 1 {
+			h1++
+		}
+	}
+	if h1 == 1 {
+		return 2
+	}
+	return 1
+}
+
+// matchSections returns, in document order, the headings at level that
+// match a policy section. Only the first heading matching a section counts.
+func matchSections(doc *lint.Document, policy *config.SectionPolicy, level int) []sectionMatch {
+	specs := map[string]int{}
+	for i, s := range policy.List {
+		for _, name := range append([]string{s.Name}, s.Aliases...) {
+			if _, ok := specs[sectionKey(name)]; !ok {
+				specs[sectionKey(name)] = i
+			}
+		}
+	}
+
+	var matches []sectionMatch
+	seen := map[int]bool{}
+	for _, h := range doc.Headings {
+		spec, ok := specs[sectionKey(h.Text)]
+		if h.Level != level || !ok || seen[spec] {
+			continue
+		}
+		seen[spec] = true
+		matches = append(matches, sectionMatch{spec: spec, heading: h})
+	}
+	return matches
+}
+
+// sectionKey reduces a section name or heading text to its lowercase words,
+// so "🚀 1. Installation:" matches "installation".
+func sectionKey(text string) string {
+	letters := strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) {
+			return unicode.ToLower(r)
+		}
+		return ' '
+	}, text)
+	return strings.Join(strings.Fields(letters), " ")
+}
+
+// missingSections returns the indexes of the required policy sections that
+// no heading matches.
+func missingSections(policy *config.SectionPolicy, matches []sectionMatch) []int {
+	present := map[int]bool{}
+	for _, m := range matches {
+		present[m.spec] = true
+	}
+	var missing []int
+	for i, s := range policy.List {
+		if s.Required && !present[i] {
+			missing = append(missing, i)
+		}
+	}
+	return missing
+}
+
+// checkRequiredSections reports every required section doc lacks, on the
+// line where it would be scaffolded.
+func checkRequiredSections(doc *lint.Document, policy *config.SectionPolicy) []lint.Finding {
+	if policy == nil {
+		return nil
+	}
+	level := sectionLevel(doc, policy)
+	matches := matchSections(doc, policy, level)
+
+	var findings []lint.Finding
+	for _, spec := range missingSections(policy, matches) {
+		s := policy.List[spec]
+		suggestion := fmt.Sprintf("add a %q section", strings.Repeat("#", level)+" "+s.Name)
+		if len(s.Aliases) > 0 {
+			suggestion += " (or name it " + strings.Join(s.Aliases, ", ") + ")"
+		}
+		findings = append(findings, lint.Finding{
+			Line:       min(sectionInsertLine(doc, level, matches, spec)+1, len(doc.Lines)),
+			Message:    fmt.Sprintf("required section %q is missing", s.Name),
+			Suggestion: suggestion,
+		})
+	}
+	return findings
+}
+
+// checkSectionOrder reports every policy section that comes after a
+// section the policy puts after it.
+func checkSectionOrder(doc *lint.Document, policy *config.SectionPolicy) []lint.Finding {
+	if policy == nil {
+		return nil
+	}
+	matches := matchSections(doc, policy, sectionLevel(doc, policy))
+
+	var findings []lint.Finding
+	for i, m := range matches {
+		for _, earlier := range matches[:i] {
+			if earlier.spec <= m.spec {
+				continue
+			}
+			findings = append(findings, lint.Finding{
+				Line:       m.heading.Line,
+				Message:    fmt.Sprintf("section %q should come before %q", m.heading.Text, earlier.heading.Text),
+				Suggestion: fmt.Sprintf("move it above line %d", earlier.heading.Line),
+			})
+			break
+		}
+	}
+	return findings
+}
+
+// sectionInsertLine returns the index of the line a missing policy section
+// goes before: the heading of the next section of the policy present in
+// doc, else the end of the previous one, else the end of the document.
+func sectionInsertLine(doc *lint.Document, level int, matches []sectionMatch, spec int) int {
+	var next, prev *sectionMatch
+	for i, m := range matches {
+		if m.spec > spec && (next == nil || m.spec < next.spec) {
+			next = &matches[i]
+		}
+		if m.spec < spec && (prev == nil || m.spec > prev.spec) {
+			prev = &matches[i]
+		}
+	}
+
+	switch {
+	case next != nil:
+		return next.heading.Line - 1
+	case prev != nil:
+		return sectionEndLine(doc, level, prev.heading)
+	default:
+		return bodyEndLine(doc)
+	}
+}
+
+// sectionEndLine returns the index of the line ending the section of h: the
+// next heading at level or shallower, else the end of the document.
+func sectionEndLine(doc *lint.Document, level int, h *generator.Heading) int {
+	for _, next := range doc.Headings {
+		if next.Line > h.Line && next.Level <= level {
+			return next.Line - 1
+		}
+	}
+	return bodyEndLine(doc)
+}
+
+// bodyEndLine returns the index of the line new sections are appended
+// before: the END_DOCS marker, or the end of the document.
+func bodyEndLine(doc *lint.Document) int {
+	for i, line := range doc.Lines {
+		if strings.TrimSpace(line) == endDocsMarker {
+			return i
+		}
+	}
+	if last := len(doc.Lines) - 1; doc.Lines[last] == "" {
+		return last
+	}
+	return len(doc.Lines)
+}
+
+// scaffoldSections adds every missing required section, with the policy's
+// placeholder as its content, where sectionInsertLine puts it.
+func scaffoldSections(doc *lint.Document, policy *config.SectionPolicy) string {
+	level := sectionLevel(doc, policy)
+	matches := matchSections(doc, policy, level)
+	placeholder := cmp.Or(policy.Placeholder, defaultPlaceholder)
+
+	inserts := map[int][]string{}
+	for _, spec := range missingSections(policy, matches) {
+		at := sectionInsertLine(doc, level, matches, spec)
+		heading := strings.Repeat("#", level) + " " + policy.List[spec].Name
+		inserts[at] = append(inserts[at], heading, "", placeholder, "")
+	}
+
+	var lines []string
+	for i := 0; i <= len(doc.Lines); i++ {
+		if block := inserts[i]; block != nil {
+			if len(lines) > 0 && lines[len(lines)-1] != "" {
+				lines = append(lines, "")
+			}
+			if i == len(doc.Lines)-1 && doc.Lines[i] == "" {
+				block = block[:len(block)-1]
+			}
+			lines = append(lines, block...)
+		}
+		if i < len(doc.Lines) {
+			lines = append(lines, doc.Lines[i])
+		}
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,168 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/lpsm-dev/gtoc/internal/config"
+	"github.com/lpsm-dev/gtoc/internal/generator"
+	"github.com/lpsm-dev/gtoc/internal/lint"
+)
+
+func testPolicy() *config.SectionPolicy {
+	return &config.SectionPolicy{
+		Scaffold: true,
+		List: []config.Section{
+			{Name: "Installation", Aliases: []string{"Install", "Getting started"}, Required: true},
+			{Name: "Usage", Required: true},
+			{Name: "Configuration", Required: true},
+			{Name: "FAQ"},
+			{Name: "License", Required: true},
+		},
+	}
+}
+
+func checkPolicy(t *testing.T, content string, policy *config.SectionPolicy) (*lint.Linter, *lint.Document, []lint.Finding) {
+	t.Helper()
+	linter, err := lint.New(sectionRules(policy), nil)
+	if err != nil {
+		t.Fatalf("lint.New failed: %v", err)
+	}
+	doc := lint.NewDocument("README.md", content, generator.NewGenerator("", 0, nil))
+	return linter, doc, linter.Check(doc)
+}
+
+func TestSectionRulesReportMissingAndMisorderedSections(t *testing.T) {
+	content := "# Service\n\nIntro.\n\n## 🚀 Install\n\nRun it.\n\n## License\n\nMIT.\n\n## 1. Usage:\n\nUse it.\n"
+	_, _, findings := checkPolicy(t, content, testPolicy())
+
+	var got []string
+	for _, f := range findings {
+		got = append(got, f.Rule+"@"+f.Message)
+	}
+	want := []string{
+		ruleRequiredSections + `@required section "Configuration" is missing`,
+		ruleSectionOrder + `@section "1. Usage:" should come before "License"`,
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("findings =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestSectionRulesWithoutPolicy(t *testing.T) {
+	linter, doc, findings := checkPolicy(t, "# Title\n\ntext\n", nil)
+	if len(findings) != 0 {
+		t.Errorf("without a policy nothing should be reported, got %+v", findings)
+	}
+	if fixed := linter.Fix(doc); fixed.Content != doc.Content {
+		t.Errorf("without a policy nothing should be fixed, got:\n%s", fixed.Content)
+	}
+}
+
+func TestScaffoldSections(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{
+			name:    "between and after present sections",
+			content: "# Service\n\n## Installation\n\nRun it.\n\n## Configuration\n\nSet it.\n",
+			want:    "# Service\n\n## Installation\n\nRun it.\n\n## Usage\n\nTODO\n\n## Configuration\n\nSet it.\n\n## License\n\nTODO\n",
+		},
+		{
+			name:    "before END_DOCS",
+			content: "# Service\n\n## Installation\n\nx\n\n## Usage\n\ny\n\n## Configuration\n\nz\n\n<!-- END_DOCS -->\n",
+			want:    "# Service\n\n## Installation\n\nx\n\n## Usage\n\ny\n\n## Configuration\n\nz\n\n## License\n\nTODO\n\n<!-- END_DOCS -->\n",
+		},
+		{
+			name:    "several top-level sections",
+			content: "# Usage\n\nUse it.\n\n# Other\n\ntext",
+			want:    "# Installation\n\nTODO\n\n# Usage\n\nUse it.\n\n# Configuration\n\nTODO\n\n# License\n\nTODO\n\n# Other\n\ntext",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			linter, doc, _ := checkPolicy(t, tt.content, testPolicy())
+			fixed := linter.Fix(doc)
+			if fixed.Content != tt.want {
+				t.Errorf("scaffolded =\n%q\nwant\n%q", fixed.Content, tt.want)
+			}
+			if findings := linter.Check(fixed); lint.Count(findings, lint.SeverityError) != 0 {
+				t.Errorf("a scaffolded document should have every required section, got %+v", findings)
+			}
+		})
+	}
+}
+
+func TestAnalyzeCommandSectionPolicy(t *testing.T) {
+	dir := t.TempDir()
+	testFile := filepath.Join(dir, "README.md")
+	if err := os.WriteFile(testFile, []byte("# Service\n\n## Usage\n\nUse it.\n"), 0644); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+	policyFile := filepath.Join(dir, "policy.json")
+	policy := `{"level": 2, "placeholder": "Coming soon.", "list": [{"name": "Installation", "required": true}, {"name": "Usage", "required": true}]}`
+	if err := os.WriteFile(policyFile, []byte(policy), 0644); err != nil {
+		t.Fatalf("failed to write policy: %v", err)
+	}
+
+	setupAnalyzeTest()
+	RootCmd.SetArgs([]string{"analyze", "--fix", "--config", layoutOnlyConfig(t), "--policy", policyFile, "--file", testFile})
+	if err := RootCmd.Execute(); err == nil || !strings.Contains(err.Error(), "1 error(s)") {
+		t.Errorf("the missing Installation section should be the only error, got %v", err)
+	}
+	if updated, _ := os.ReadFile(testFile); strings.Contains(string(updated), "## Installation") {
+		t.Errorf("sections should only be scaffolded when the policy asks for it, got:\n%s", updated)
+	}
+
+	scaffolding := strings.Replace(policy, `"level": 2,`, `"level": 2, "scaffold": true,`, 1)
+	if err := os.WriteFile(policyFile, []byte(scaffolding), 0644); err != nil {
+		t.Fatalf("failed to write policy: %v", err)
+	}
+	setupAnalyzeTest()
+	RootCmd.SetArgs([]string{"analyze", "--fix", "--config", layoutOnlyConfig(t), "--policy", policyFile, "--file", testFile})
+	if err := RootCmd.Execute(); err != nil {
+		t.Fatalf("analyze --fix failed: %v", err)
+	}
+	updated, _ := os.ReadFile(testFile)
+	if !strings.Contains(string(updated), "## Installation\n\nComing soon.\n\n## Usage") {
+		t.Errorf("the Installation section should be scaffolded before Usage, got:\n%s", updated)
+	}
+}
+
+func TestAnalyzeCommandListsScaffoldedSectionsInTOC(t *testing.T) {
+	dir := t.TempDir()
+	testFile := filepath.Join(dir, "README.md")
+	policyFile := filepath.Join(dir, "policy.json")
+	writeTestFiles(t, map[string]string{
+		testFile:   "# Service\n\n<!-- START_TABLE_OF_CONTENTS -->\n<!-- END_TABLE_OF_CONTENTS -->\n\n## Usage\n\nUse it.\n",
+		policyFile: `{"level": 2, "scaffold": true, "list": [{"name": "Installation", "required": true}, {"name": "Usage", "required": true}]}`,
+	})
+
+	updated := runAnalyzeFix(t, testFile, "--policy", policyFile)
+	if !strings.Contains(updated, "[Installation](#installation)") {
+		t.Errorf("the table of contents should list the scaffolded section, got:\n%s", updated)
+	}
+}
+
+func TestAnalyzeCommandRejectsInvalidPolicy(t *testing.T) {
+	dir := t.TempDir()
+	testFile := filepath.Join(dir, "README.md")
+	if err := os.WriteFile(testFile, []byte("# Title\n"), 0644); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+	policyFile := filepath.Join(dir, "policy.json")
+	if err := os.WriteFile(policyFile, []byte(`{"list": [{"name": " "}]}`), 0644); err != nil {
+		t.Fatalf("failed to write policy: %v", err)
+	}
+
+	setupAnalyzeTest()
+	RootCmd.SetArgs([]string{"analyze", "--policy", policyFile, "--file", testFile})
+	if err := RootCmd.Execute(); err == nil || !strings.Contains(err.Error(), "has no name") {
+		t.Errorf("expected an invalid policy error, got %v", err)
+	}
+}
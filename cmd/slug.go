@@ -0,0 +1,20 @@
+package cmd
+
+import (
+	"github.com/lpsm-dev/gtoc/internal/generator"
+	"github.com/spf13/cobra"
+)
+
+// slugStyle is the --slug flag value shared by the subcommands that
+// compute anchors.
+var slugStyle string
+
+// addSlugFlag registers the --slug flag.
+func addSlugFlag(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&slugStyle, "slug", generator.SlugGitHub, "Anchor style: github (keeps unicode letters) or ascii (transliterates, e.g. ação -> acao)")
+}
+
+// slugger returns the Slugger selected by --slug.
+func slugger() (generator.Slugger, error) {
+	return generator.SluggerByName(slugStyle)
+}
@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/lpsm-dev/gtoc/internal/fsutil"
+	"github.com/lpsm-dev/gtoc/internal/generator"
+	"github.com/lpsm-dev/gtoc/internal/logger"
+	"github.com/spf13/cobra"
+)
+
+var syncDepth int
+
+// errOutOfSync is returned when a translation's heading structure differs
+// from the reference document.
+var errOutOfSync = errors.New("translations are out of sync")
+
+// syncCheckCmd compares the heading structure of translated documents.
+var syncCheckCmd = &cobra.Command{
+	Use:   "sync-check <reference> <translation>...",
+	Short: "Check that translated markdown files have the same sections",
+	Long: `Compare the heading structure of each translation with the reference
+document and report sections that are missing, extra, out of order or at a
+different level. Sections are paired by position and heading level; put
+<!-- gtoc:id name --> before a heading in every language to pair it
+explicitly, which also detects reordered sections.
+
+The command exits with a non-zero status when any translation differs,
+so it can run in CI.
+
+Example:
+  gtoc sync-check README.md README_en.md
+  gtoc sync-check docs/guide.md docs/guide_es.md docs/guide_fr.md --depth 2`,
+	Args: cobra.MinimumNArgs(2),
+	RunE: runSyncCheck,
+}
+
+// runSyncCheck compares every translation with the reference and fails if
+// any of them differs.
+func runSyncCheck(cmd *cobra.Command, args []string) error {
+	refPath := args[0]
+	ref, err := readOutline(refPath)
+	if err != nil {
+		return err
+	}
+
+	total := 0
+	for _, path := range args[1:] {
+		other, err := readOutline(path)
+		if err != nil {
+			return err
+		}
+
+		diffs := generator.CompareOutlines(ref, other)
+		logger.Debug("Compared outlines", "reference", refPath, "translation", path, "differences", len(diffs))
+		if len(diffs) == 0 {
+			fmt.Printf("%s: in sync with %s\n", path, refPath)
+			continue
+		}
+		for _, d := range diffs {
+			fmt.Println(describeDifference(refPath, path, d))
+		}
+		total += len(diffs)
+	}
+
+	if total > 0 {
+		return fmt.Errorf("%w: %d difference(s)", errOutOfSync, total)
+	}
+	return nil
+}
+
+// readOutline returns the sections of the markdown file at path.
+func readOutline(path string) ([]generator.Section, error) {
+	absFilePath, err := validateFileExists(path)
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := fsutil.ReadText(absFilePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+	return generator.NewGenerator(absFilePath, syncDepth, nil).Outline(content), nil
+}
+
+// describeDifference formats d as a "file:line: message" report line.
+func describeDifference(refPath, path string, d generator.Difference) string {
+	switch d.Kind {
+	case generator.DifferenceMissing:
+		return fmt.Sprintf("%s: missing section %s (%s:%d)", path, sectionLabel(d.Reference), refPath, d.Reference.Line)
+	case generator.DifferenceExtra:
+		return fmt.Sprintf("%s:%d: extra section %s not in %s", path, d.Other.Line, sectionLabel(d.Other), refPath)
+	case generator.DifferenceReordered:
+		return fmt.Sprintf("%s:%d: section %s is out of order (%s:%d)", path, d.Other.Line, sectionLabel(d.Other), refPath, d.Reference.Line)
+	default:
+		return fmt.Sprintf("%s:%d: section %s is level %d but level %d in %s:%d", path, d.Other.Line,
+			sectionLabel(d.Other), d.Other.Level, d.Reference.Level, refPath, d.Reference.Line)
+	}
+}
+
+// sectionLabel renders a section as its markdown heading, quoted.
+func sectionLabel(s *generator.Section) string {
+	return fmt.Sprintf("%q", strings.Repeat("#", s.Level)+" "+s.Text)
+}
+
+func init() {
+	syncCheckCmd.Flags().IntVar(&syncDepth, "depth", 0, "Maximum heading depth to compare (0 for unlimited)")
+}
@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSyncCheckCommand(t *testing.T) {
+	tempDir := t.TempDir()
+	reference := filepath.Join(tempDir, "README.md")
+	inSync := filepath.Join(tempDir, "README_en.md")
+	drifted := filepath.Join(tempDir, "README_es.md")
+	for path, content := range map[string]string{
+		reference: "# Visão Geral\n\n## Objetivo\n\n# Uso\n",
+		inSync:    "# Overview\n\n## Goal\n\n# Usage\n",
+		drifted:   "# Resumen\n\n# Uso\n\n## Ejemplos\n",
+	} {
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to create test file: %v", err)
+		}
+	}
+
+	resetRootCmd()
+	syncDepth = 0
+	RootCmd.SetArgs([]string{"sync-check", reference, inSync})
+	if err := RootCmd.Execute(); err != nil {
+		t.Errorf("sync-check should pass for matching structures, got %v", err)
+	}
+
+	resetRootCmd()
+	RootCmd.SetArgs([]string{"sync-check", reference, inSync, drifted})
+	if err := RootCmd.Execute(); !errors.Is(err, errOutOfSync) {
+		t.Errorf("sync-check should fail with errOutOfSync for a drifted translation, got %v", err)
+	}
+
+	resetRootCmd()
+	RootCmd.SetArgs([]string{"sync-check", reference})
+	if err := RootCmd.Execute(); err == nil {
+		t.Error("sync-check should require at least one translation")
+	}
+}
@@ -0,0 +1,26 @@
+package cmd
+
+import (
+	"github.com/lpsm-dev/gtoc/internal/fsutil"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// Flags shared by every subcommand that rewrites files.
+	backupFiles      bool
+	noFollowSymlinks bool
+)
+
+// addWriteFlags registers the flags controlling how files are rewritten.
+func addWriteFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&backupFiles, "backup", false, "Keep the previous content of each rewritten file as <file>.bak")
+	cmd.Flags().BoolVar(&noFollowSymlinks, "no-follow-symlinks", false, "Refuse to write through symbolic links instead of updating their target")
+}
+
+// writeOptions returns the fsutil options selected by the write flags.
+func writeOptions() fsutil.Options {
+	return fsutil.Options{
+		Backup:           backupFiles,
+		NoFollowSymlinks: noFollowSymlinks,
+	}
+}
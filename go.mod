@@ -8,6 +8,7 @@ require (
 	charm.land/glamour/v2 v2.0.1
 	charm.land/log/v2 v2.0.0
 	github.com/spf13/cobra v1.10.2
+	golang.org/x/text v0.40.0
 )
 
 require (
@@ -40,5 +41,4 @@ require (
 	golang.org/x/net v0.57.0 // indirect
 	golang.org/x/sync v0.22.0 // indirect
 	golang.org/x/sys v0.47.0 // indirect
-	golang.org/x/text v0.40.0 // indirect
 )
@@ -0,0 +1,113 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/lpsm-dev/gtoc/internal/i18n"
+)
+
+// DefaultFile is the project configuration file read from the working
+// directory when no other path is given.
+const DefaultFile = ".gtoc.json"
+
+// Config is the project configuration.
+type Config struct {
+	// Lang is the language of documents whose language is not given with
+	// --lang or detected from their front matter or file name.
+	Lang string `json:"lang,omitempty"`
+	// Messages overrides the built-in strings, keyed by language tag.
+	Messages map[string]i18n.Messages `json:"messages,omitempty"`
+	// Rules sets the severity of analyze rules, keyed by rule ID: "error",
+	// "warning", "info", or "off" to disable the rule.
+	Rules map[string]string `json:"rules,omitempty"`
+	// Sections is the policy of the sections a README must or may have,
+	// checked by analyze.
+	Sections *SectionPolicy `json:"sections,omitempty"`
+}
+
+// SectionPolicy lists the sections a README may have, in the order they
+// are allowed in. Sections not listed may appear anywhere.
+type SectionPolicy struct {
+	// Level is the heading level of the sections. Zero means 2 for a
+	// document with a single top-level heading and 1 otherwise.
+	Level int `json:"level,omitempty"`
+	// Scaffold lets analyze --fix add missing required sections with
+	// Placeholder as their content ("TODO" when empty).
+	Scaffold    bool   `json:"scaffold,omitempty"`
+	Placeholder string `json:"placeholder,omitempty"`
+	// List holds the known sections in their allowed order.
+	List []Section `json:"list"`
+}
+
+// Section is a section of a SectionPolicy. A heading matches it when its
+// text is Name or one of Aliases, ignoring case, punctuation, numbers and
+// emoji.
+type Section struct {
+	Name     string   `json:"name"`
+	Aliases  []string `json:"aliases,omitempty"`
+	Required bool     `json:"required,omitempty"`
+}
+
+// Load reads the JSON configuration file at path. Unknown fields are
+// rejected so typos do not go unnoticed. A missing file is reported with an
+// error wrapping os.ErrNotExist.
+func Load(path string) (Config, error) {
+	var cfg Config
+
+	f, err := os.Open(path)
+	if err != nil {
+		return cfg, err
+	}
+	defer f.Close()
+
+	dec := json.NewDecoder(f)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&cfg); err != nil {
+		return cfg, fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+	if cfg.Sections != nil {
+		if err := cfg.Sections.Validate(); err != nil {
+			return cfg, fmt.Errorf("invalid config file %s: %w", path, err)
+		}
+	}
+	return cfg, nil
+}
+
+// LoadPolicy reads a section policy from the JSON file at path, which holds
+// what the "sections" field of a config file would.
+func LoadPolicy(path string) (SectionPolicy, error) {
+	var policy SectionPolicy
+
+	f, err := os.Open(path)
+	if err != nil {
+		return policy, err
+	}
+	defer f.Close()
+
+	dec := json.NewDecoder(f)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&policy); err != nil {
+		return policy, fmt.Errorf("invalid policy file %s: %w", path, err)
+	}
+	if err := policy.Validate(); err != nil {
+		return policy, fmt.Errorf("invalid policy file %s: %w", path, err)
+	}
+	return policy, nil
+}
+
+// Validate reports a policy with an out-of-range level or a section without
+// a name.
+func (p SectionPolicy) Validate() error {
+	if p.Level < 0 || p.Level > 6 {
+		return fmt.Errorf("section level %d is not between 1 and 6", p.Level)
+	}
+	for i, s := range p.List {
+		if strings.TrimSpace(s.Name) == "" {
+			return fmt.Errorf("section %d has no name", i+1)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,183 @@
+// Package diff computes line-based unified diffs between two texts, for
+// previewing changes before they are written.
+package diff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// op is one line of an edit script: kept (' '), deleted ('-') or inserted
+// ('+'). old and new count the lines of each text before it.
+type op struct {
+	kind     byte
+	text     string
+	old, new int
+}
+
+// Unified returns a unified diff that turns a, named oldName, into b, named
+// newName, with context unchanged lines around each change. It returns ""
+// when the texts are equal.
+func Unified(oldName, newName, a, b string, context int) string {
+	if a == b {
+		return ""
+	}
+	ops := edits(splitLines(a), splitLines(b))
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "--- %s\n+++ %s\n", oldName, newName)
+	for _, h := range hunks(ops, context) {
+		writeHunk(&sb, ops[h[0]:h[1]])
+	}
+	return sb.String()
+}
+
+// splitLines splits text into lines that keep their "\n", so a missing
+// final newline shows up as a change.
+func splitLines(text string) []string {
+	lines := strings.SplitAfter(text, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// edits returns the edit script turning a into b. The common prefix and
+// suffix are matched first, so only the changed middle goes through the
+// quadratic longest common subsequence.
+func edits(a, b []string) []op {
+	pre := 0
+	for pre < len(a) && pre < len(b) && a[pre] == b[pre] {
+		pre++
+	}
+	suf := 0
+	for suf < len(a)-pre && suf < len(b)-pre && a[len(a)-1-suf] == b[len(b)-1-suf] {
+		suf++
+	}
+
+	kinds := make([]byte, 0, len(a)+len(b))
+	kinds = append(kinds, []byte(strings.Repeat(" ", pre))...)
+	kinds = append(kinds, lcsEdits(a[pre:len(a)-suf], b[pre:len(b)-suf])...)
+	kinds = append(kinds, []byte(strings.Repeat(" ", suf))...)
+	return number(kinds, a, b)
+}
+
+// lcsEdits returns the kinds of the edit script turning a into b along their
+// longest common subsequence, with deletions before insertions.
+func lcsEdits(a, b []string) []byte {
+	return backtrack(lcsTable(a, b), a, b)
+}
+
+// lcsTable returns the table of longest common subsequence lengths, where
+// table[i][j] is the length for a[i:] and b[j:].
+func lcsTable(a, b []string) [][]int {
+	n, m := len(a), len(b)
+	table := make([][]int, n+1)
+	for i := range table {
+		table[i] = make([]int, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				table[i][j] = table[i+1][j+1] + 1
+			} else {
+				table[i][j] = max(table[i+1][j], table[i][j+1])
+			}
+		}
+	}
+	return table
+}
+
+// backtrack walks table from the start of a and b and returns the kinds of
+// the edit script it follows, preferring deletions over insertions.
+func backtrack(table [][]int, a, b []string) []byte {
+	n, m := len(a), len(b)
+	var kinds []byte
+	i, j := 0, 0
+	for i < n || j < m {
+		switch {
+		case i < n && j < m && a[i] == b[j]:
+			kinds = append(kinds, ' ')
+			i++
+			j++
+		case j == m || (i < n && table[i+1][j] >= table[i][j+1]):
+			kinds = append(kinds, '-')
+			i++
+		default:
+			kinds = append(kinds, '+')
+			j++
+		}
+	}
+	return kinds
+}
+
+// number turns kinds into ops carrying their lines of a and b.
+func number(kinds []byte, a, b []string) []op {
+	ops := make([]op, len(kinds))
+	i, j := 0, 0
+	for k, kind := range kinds {
+		ops[k] = op{kind: kind, old: i, new: j}
+		switch kind {
+		case '-':
+			ops[k].text = a[i]
+			i++
+		case '+':
+			ops[k].text = b[j]
+			j++
+		default:
+			ops[k].text = a[i]
+			i++
+			j++
+		}
+	}
+	return ops
+}
+
+// hunks returns the [start, end) ranges of ops to print: every change with
+// up to context kept lines on each side, merging ranges that touch.
+func hunks(ops []op, context int) [][2]int {
+	var ranges [][2]int
+	for k, o := range ops {
+		if o.kind == ' ' {
+			continue
+		}
+		start, end := max(0, k-context), min(len(ops), k+context+1)
+		if n := len(ranges); n > 0 && start <= ranges[n-1][1] {
+			ranges[n-1][1] = end
+			continue
+		}
+		ranges = append(ranges, [2]int{start, end})
+	}
+	return ranges
+}
+
+// writeHunk writes the header and lines of a hunk.
+func writeHunk(sb *strings.Builder, ops []op) {
+	oldCount, newCount := 0, 0
+	for _, o := range ops {
+		if o.kind != '+' {
+			oldCount++
+		}
+		if o.kind != '-' {
+			newCount++
+		}
+	}
+	fmt.Fprintf(sb, "@@ -%s +%s @@\n", hunkRange(ops[0].old, oldCount), hunkRange(ops[0].new, newCount))
+
+	for _, o := range ops {
+		sb.WriteByte(o.kind)
+		sb.WriteString(o.text)
+		if !strings.HasSuffix(o.text, "\n") {
+			sb.WriteString("\n\\ No newline at end of file\n")
+		}
+	}
+}
+
+// hunkRange formats the "start,count" of a hunk that begins after before
+// lines. An empty range names the line before it, as diff does.
+func hunkRange(before, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", before)
+	}
+	return fmt.Sprintf("%d,%d", before+1, count)
+}
@@ -0,0 +1,273 @@
+package fsutil
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// utf8BOM is the byte order mark some editors prepend to UTF-8 files.
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// defaultMode is used when writing a file that does not exist yet.
+const defaultMode = os.FileMode(0644)
+
+// Locking parameters for the advisory lock taken around each write.
+const (
+	lockSuffix        = ".gtoc.lock"
+	lockRetryInterval = 50 * time.Millisecond
+	staleLockAge      = time.Minute
+)
+
+// lockTimeout is how long WriteText waits for another process to release
+// the lock. It is a variable so tests can shorten it.
+var lockTimeout = 5 * time.Second
+
+// ErrModified is returned by WriteText when the file changed on disk after
+// it was read, so writing would silently discard someone else's edit.
+var ErrModified = errors.New("file was modified since it was read")
+
+// ErrLocked is returned by WriteText when another gtoc process holds the
+// lock on the file for longer than the lock timeout.
+var ErrLocked = errors.New("file is locked by another gtoc process")
+
+// Options controls how WriteText replaces a file.
+type Options struct {
+	// Backup keeps the previous content of the file at path + ".bak".
+	Backup bool
+	// NoFollowSymlinks makes writing through a symbolic link an error
+	// instead of updating the file the link points to.
+	NoFollowSymlinks bool
+	// Expect is the fingerprint of the content the new text was derived
+	// from. When set, the write is aborted with ErrModified if the file no
+	// longer matches it.
+	Expect string
+}
+
+// Snapshot is the normalized text of a file together with a fingerprint of
+// the bytes it was read from.
+type Snapshot struct {
+	Text        string
+	Fingerprint string
+}
+
+// format records the byte-level conventions of a text file that gtoc
+// normalizes away while processing and restores when writing.
+type format struct {
+	bom  bool
+	crlf bool
+}
+
+// ReadText reads the file at path as text normalized for processing: a
+// leading UTF-8 BOM is removed and, in files that predominantly use CRLF
+// line endings, every CRLF is turned into LF. WriteText restores both.
+func ReadText(path string) (string, error) {
+	snap, err := ReadSnapshot(path)
+	return snap.Text, err
+}
+
+// ReadSnapshot reads the file at path like ReadText and also fingerprints
+// its on-disk bytes, for use as Options.Expect when writing it back.
+func ReadSnapshot(path string) (Snapshot, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return Snapshot{}, err
+	}
+	text, _ := decode(raw)
+	return Snapshot{Text: text, Fingerprint: fingerprint(raw)}, nil
+}
+
+// WriteText atomically replaces the file at path with content, re-applying
+// the BOM and line endings of the file being replaced. The content is
+// written to a temporary file in the same directory and renamed over the
+// original, so a crash never leaves a truncated file behind. The original
+// permission bits are preserved. A symbolic link is followed, so the link
+// is kept and its target updated, unless opts.NoFollowSymlinks is set.
+//
+// The write holds an advisory lock shared by every gtoc process, and when
+// opts.Expect is set it is only performed if the file is still the one
+// that was read.
+func WriteText(path, content string, opts Options) error {
+	target, err := resolveTarget(path, opts)
+	if err != nil {
+		return err
+	}
+
+	unlock, err := lock(target)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	mode, f, err := inspectTarget(target, path, opts)
+	if err != nil {
+		return err
+	}
+
+	if opts.Backup {
+		if err := backup(target, mode); err != nil {
+			return err
+		}
+	}
+
+	return writeAtomic(target, f.encode(content), mode)
+}
+
+// inspectTarget returns the permission bits and format of the file a write
+// to path replaces at target, or the defaults when there is none yet. It
+// fails with ErrModified when opts.Expect is set and the file changed or
+// was removed since it was read.
+func inspectTarget(target, path string, opts Options) (os.FileMode, format, error) {
+	info, err := os.Stat(target)
+	if errors.Is(err, os.ErrNotExist) {
+		if opts.Expect != "" {
+			return 0, format{}, fmt.Errorf("%w: %s was removed", ErrModified, path)
+		}
+		return defaultMode, format{}, nil
+	}
+	if err != nil {
+		return 0, format{}, err
+	}
+
+	original, err := os.ReadFile(target)
+	if err != nil {
+		return 0, format{}, err
+	}
+	if opts.Expect != "" && fingerprint(original) != opts.Expect {
+		return 0, format{}, fmt.Errorf("%w: %s", ErrModified, path)
+	}
+	_, f := decode(original)
+	return info.Mode().Perm(), f, nil
+}
+
+// resolveTarget returns the file that writing to path should replace.
+func resolveTarget(path string, opts Options) (string, error) {
+	info, err := os.Lstat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return path, nil
+	}
+	if err != nil {
+		return "", err
+	}
+	if info.Mode()&os.ModeSymlink == 0 {
+		return path, nil
+	}
+
+	if opts.NoFollowSymlinks {
+		return "", fmt.Errorf("refusing to write through symbolic link %s", path)
+	}
+	return filepath.EvalSymlinks(path)
+}
+
+// backup copies the current content of path to path + ".bak". A missing
+// original is not an error: there is nothing to back up.
+func backup(path string, mode os.FileMode) error {
+	original, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if err := writeAtomic(path+".bak", original, mode); err != nil {
+		return fmt.Errorf("failed to write backup: %w", err)
+	}
+	return nil
+}
+
+// writeAtomic writes data to a temporary file next to path, flushes it to
+// disk, and renames it over path.
+func writeAtomic(path string, data []byte, mode os.FileMode) (err error) {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".gtoc-*")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tmp.Close()
+			os.Remove(tmp.Name())
+		}
+	}()
+
+	if _, err = io.Copy(tmp, bytes.NewReader(data)); err != nil {
+		return err
+	}
+	if err = tmp.Chmod(mode); err != nil {
+		return err
+	}
+	if err = tmp.Sync(); err != nil {
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// lock takes the advisory lock for path by exclusively creating a lock file
+// next to it, retrying until lockTimeout. A lock file older than
+// staleLockAge is assumed to be left over from a crashed process and is
+// removed. The returned function releases the lock.
+func lock(path string) (func(), error) {
+	lockPath := filepath.Join(filepath.Dir(path), "."+filepath.Base(path)+lockSuffix)
+	deadline := time.Now().Add(lockTimeout)
+
+	for {
+		f, err := os.OpenFile(lockPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+		if err == nil {
+			fmt.Fprintf(f, "%d\n", os.Getpid())
+			f.Close()
+			return func() { os.Remove(lockPath) }, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, fmt.Errorf("failed to create lock file: %w", err)
+		}
+
+		if info, statErr := os.Stat(lockPath); statErr == nil && time.Since(info.ModTime()) > staleLockAge {
+			os.Remove(lockPath)
+			continue
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("%w: %s (remove %s if no gtoc process is running)", ErrLocked, path, lockPath)
+		}
+		time.Sleep(lockRetryInterval)
+	}
+}
+
+// fingerprint returns the hex-encoded SHA-256 digest of raw.
+func fingerprint(raw []byte) string {
+	sum := sha256.Sum256(raw)
+	return hex.EncodeToString(sum[:])
+}
+
+// decode strips a UTF-8 BOM and, when most line breaks are CRLF, converts
+// them to LF, returning the normalized text and the detected format.
+func decode(raw []byte) (string, format) {
+	f := format{bom: bytes.HasPrefix(raw, utf8BOM)}
+	text := string(bytes.TrimPrefix(raw, utf8BOM))
+
+	crlf := strings.Count(text, "\r\n")
+	if lf := strings.Count(text, "\n"); crlf > 0 && crlf*2 >= lf {
+		f.crlf = true
+		text = strings.ReplaceAll(text, "\r\n", "\n")
+	}
+	return text, f
+}
+
+// encode re-applies the format to normalized text.
+func (f format) encode(text string) []byte {
+	if f.crlf {
+		text = strings.ReplaceAll(strings.ReplaceAll(text, "\r\n", "\n"), "\n", "\r\n")
+	}
+	if f.bom {
+		return append(append([]byte{}, utf8BOM...), text...)
+	}
+	return []byte(text)
+}
@@ -0,0 +1,224 @@
+package fsutil
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeFixture creates a file with the given raw content and mode in a
+// fresh temp directory and returns its path.
+func writeFixture(t *testing.T, content string, mode os.FileMode) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "doc.md")
+	if err := os.WriteFile(path, []byte(content), mode); err != nil {
+		t.Fatalf("failed to create fixture: %v", err)
+	}
+	if err := os.Chmod(path, mode); err != nil {
+		t.Fatalf("failed to chmod fixture: %v", err)
+	}
+	return path
+}
+
+// readRaw returns the raw bytes of path as a string.
+func readRaw(t *testing.T, path string) string {
+	t.Helper()
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read %s: %v", path, err)
+	}
+	return string(raw)
+}
+
+func TestWriteTextPreservesMode(t *testing.T) {
+	path := writeFixture(t, "# Old\n", 0600)
+
+	if err := WriteText(path, "# New\n", Options{}); err != nil {
+		t.Fatalf("WriteText failed: %v", err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("stat failed: %v", err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("mode = %v, want 0600", info.Mode().Perm())
+	}
+	if got := readRaw(t, path); got != "# New\n" {
+		t.Errorf("content = %q, want %q", got, "# New\n")
+	}
+}
+
+func TestWriteTextLeavesNoTempFiles(t *testing.T) {
+	path := writeFixture(t, "# Old\n", 0644)
+
+	if err := WriteText(path, "# New\n", Options{}); err != nil {
+		t.Fatalf("WriteText failed: %v", err)
+	}
+
+	entries, err := os.ReadDir(filepath.Dir(path))
+	if err != nil {
+		t.Fatalf("failed to list dir: %v", err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("expected only the target file to remain, found %d entries", len(entries))
+	}
+}
+
+func TestReadWriteTextRoundTripsLineEndingsAndBOM(t *testing.T) {
+	path := writeFixture(t, "\xEF\xBB\xBF# Title\r\n\r\nBody\r\n", 0644)
+
+	text, err := ReadText(path)
+	if err != nil {
+		t.Fatalf("ReadText failed: %v", err)
+	}
+	if text != "# Title\n\nBody\n" {
+		t.Errorf("ReadText() = %q, want BOM and CRLF normalized away", text)
+	}
+
+	if err := WriteText(path, text+"More\n", Options{}); err != nil {
+		t.Fatalf("WriteText failed: %v", err)
+	}
+	if got, want := readRaw(t, path), "\xEF\xBB\xBF# Title\r\n\r\nBody\r\nMore\r\n"; got != want {
+		t.Errorf("written content = %q, want %q", got, want)
+	}
+}
+
+func TestWriteTextFollowsSymlinks(t *testing.T) {
+	target := writeFixture(t, "# Old\n", 0644)
+	link := filepath.Join(t.TempDir(), "link.md")
+	if err := os.Symlink(target, link); err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+
+	if err := WriteText(link, "# New\n", Options{}); err != nil {
+		t.Fatalf("WriteText failed: %v", err)
+	}
+	if info, err := os.Lstat(link); err != nil || info.Mode()&os.ModeSymlink == 0 {
+		t.Error("the symbolic link should be kept")
+	}
+	if got := readRaw(t, target); got != "# New\n" {
+		t.Errorf("link target content = %q, want %q", got, "# New\n")
+	}
+
+	if err := WriteText(link, "# Newer\n", Options{NoFollowSymlinks: true}); err == nil {
+		t.Error("WriteText with NoFollowSymlinks should refuse a symbolic link")
+	}
+	if got := readRaw(t, target); got != "# New\n" {
+		t.Error("a refused write must not touch the link target")
+	}
+}
+
+func TestWriteTextBackup(t *testing.T) {
+	path := writeFixture(t, "# Old\n", 0640)
+
+	if err := WriteText(path, "# New\n", Options{Backup: true}); err != nil {
+		t.Fatalf("WriteText failed: %v", err)
+	}
+
+	if got := readRaw(t, path+".bak"); got != "# Old\n" {
+		t.Errorf("backup content = %q, want the original content", got)
+	}
+	if info, err := os.Stat(path + ".bak"); err != nil || info.Mode().Perm() != 0640 {
+		t.Errorf("backup should keep the original mode, got %v (%v)", info.Mode().Perm(), err)
+	}
+}
+
+func TestDecodeMixedLineEndings(t *testing.T) {
+	text, f := decode([]byte("a\nb\nc\r\n"))
+	if f.crlf || !strings.Contains(text, "\r\n") {
+		t.Errorf("a mostly-LF file should be left as-is, got crlf=%v text=%q", f.crlf, text)
+	}
+}
+
+func TestWriteTextDetectsConcurrentModification(t *testing.T) {
+	path := writeFixture(t, "# Original\n", 0644)
+
+	snap, err := ReadSnapshot(path)
+	if err != nil {
+		t.Fatalf("ReadSnapshot failed: %v", err)
+	}
+
+	// Simulate an editor saving the file between the read and the write.
+	if err := os.WriteFile(path, []byte("# Edited elsewhere\n"), 0644); err != nil {
+		t.Fatalf("failed to modify file: %v", err)
+	}
+
+	err = WriteText(path, snap.Text+"\nMore\n", Options{Expect: snap.Fingerprint})
+	if !errors.Is(err, ErrModified) {
+		t.Fatalf("WriteText() error = %v, want ErrModified", err)
+	}
+	if got := readRaw(t, path); got != "# Edited elsewhere\n" {
+		t.Error("the concurrent edit must not be overwritten")
+	}
+
+	fresh, err := ReadSnapshot(path)
+	if err != nil {
+		t.Fatalf("ReadSnapshot failed: %v", err)
+	}
+	if err := WriteText(path, fresh.Text, Options{Expect: fresh.Fingerprint}); err != nil {
+		t.Errorf("WriteText with a current fingerprint should succeed, got %v", err)
+	}
+}
+
+func TestWriteTextDetectsConcurrentRemoval(t *testing.T) {
+	path := writeFixture(t, "# Original\n", 0644)
+
+	snap, err := ReadSnapshot(path)
+	if err != nil {
+		t.Fatalf("ReadSnapshot failed: %v", err)
+	}
+	if err := os.Remove(path); err != nil {
+		t.Fatalf("failed to remove file: %v", err)
+	}
+
+	err = WriteText(path, snap.Text, Options{Expect: snap.Fingerprint})
+	if !errors.Is(err, ErrModified) {
+		t.Fatalf("WriteText() error = %v, want ErrModified", err)
+	}
+	if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+		t.Error("a file removed since it was read must not be recreated")
+	}
+}
+
+func TestWriteTextRespectsLock(t *testing.T) {
+	path := writeFixture(t, "# Original\n", 0644)
+	lockTimeout = 100 * time.Millisecond
+	t.Cleanup(func() { lockTimeout = 5 * time.Second })
+
+	unlock, err := lock(path)
+	if err != nil {
+		t.Fatalf("lock failed: %v", err)
+	}
+
+	if err := WriteText(path, "# New\n", Options{}); !errors.Is(err, ErrLocked) {
+		t.Errorf("WriteText() while locked: error = %v, want ErrLocked", err)
+	}
+
+	unlock()
+	if err := WriteText(path, "# New\n", Options{}); err != nil {
+		t.Errorf("WriteText() after unlock failed: %v", err)
+	}
+}
+
+func TestLockRemovesStaleLock(t *testing.T) {
+	path := writeFixture(t, "# Original\n", 0644)
+	lockPath := filepath.Join(filepath.Dir(path), "."+filepath.Base(path)+lockSuffix)
+	if err := os.WriteFile(lockPath, []byte("12345\n"), 0644); err != nil {
+		t.Fatalf("failed to create lock file: %v", err)
+	}
+	old := time.Now().Add(-2 * staleLockAge)
+	if err := os.Chtimes(lockPath, old, old); err != nil {
+		t.Fatalf("failed to age lock file: %v", err)
+	}
+
+	if err := WriteText(path, "# New\n", Options{}); err != nil {
+		t.Errorf("a stale lock should be taken over, got %v", err)
+	}
+	if _, err := os.Stat(lockPath); !os.IsNotExist(err) {
+		t.Error("the lock file should be removed after the write")
+	}
+}
@@ -0,0 +1,151 @@
+package generator
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Directive names recognized in <!-- gtoc:name --> comments.
+const (
+	// directiveAppendix switches the following top-level sections to letter
+	// numbering (A., A.1., ...) with the counters restarted.
+	directiveAppendix = "appendix"
+	// directivePart marks the next heading as a part heading that groups the
+	// chapters after it. It is left unnumbered and does not advance the
+	// chapter counters, so chapters keep their numbers across parts.
+	directivePart = "part"
+)
+
+// directivePattern matches a line holding only a gtoc directive comment such
+// as "<!-- gtoc:appendix -->", capturing its name and optional argument.
+var directivePattern = regexp.MustCompile(`^\s*<!--\s*gtoc:([a-z-]+)(?:\s+(.*?))?\s*-->\s*$`)
+
+// directive is an in-document gtoc instruction, the 1-based line it
+// appears on and the 1-based line of the first heading after it, or 0 when
+// no heading follows.
+type directive struct {
+	name    string
+	arg     string
+	line    int
+	heading int
+}
+
+// findDirectives returns every directive in lines, skipping fenced code
+// blocks and any existing TOC block. Each directive is tied to the first
+// heading after it among all headings, before any depth or filter applies,
+// so a filtered-out heading never passes its directives on to the next one.
+func findDirectives(lines []string) []directive {
+	var out []directive
+	pending := 0
+	filter := &lineFilter{}
+	for i, line := range lines {
+		if filter.skip(line) {
+			continue
+		}
+		if m := directivePattern.FindStringSubmatch(line); m != nil {
+			out = append(out, directive{name: m[1], arg: strings.TrimSpace(m[2]), line: i + 1})
+			continue
+		}
+		if headingPattern.MatchString(line) {
+			for ; pending < len(out); pending++ {
+				out[pending].heading = i + 1
+			}
+		}
+	}
+	return out
+}
+
+// firstDirectiveLine returns the line of the first directive called name,
+// or 0 when there is none.
+func firstDirectiveLine(directives []directive, name string) int {
+	for _, d := range directives {
+		if d.name == name {
+			return d.line
+		}
+	}
+	return 0
+}
+
+// headingDirectives returns the argument of each directive called name,
+// keyed by the 1-based line of the heading it applies to. Directives
+// without an argument or a heading after them are ignored.
+func headingDirectives(directives []directive, name string) map[int]string {
+	args := map[int]string{}
+	for _, d := range directives {
+		if d.name == name && d.arg != "" && d.heading > 0 {
+			args[d.heading] = d.arg
+		}
+	}
+	return args
+}
+
+// attachDirectives returns, for each heading line (1-based), the argument
+// of the directive called name that sits right before it, or "". A
+// directive whose heading is not among lines, such as one the filters left
+// out, is dropped; directives without an argument are ignored.
+func attachDirectives(directives []directive, name string, lines []int) []string {
+	byHeading := headingDirectives(directives, name)
+	args := make([]string, len(lines))
+	for i, line := range lines {
+		args[i] = byHeading[line]
+	}
+	return args
+}
+
+// outlineEntry is the position and level of a heading to be numbered.
+type outlineEntry struct {
+	line  int
+	level int
+}
+
+// numberOutline returns the outline number of every entry under scheme,
+// honoring appendix and part directives. An entry outside the numbered
+// range, or a part heading, gets "".
+func numberOutline(scheme NumberingScheme, entries []outlineEntry, directives []directive) []string {
+	parts := partEntries(entries, directives)
+	numbers := newNumberer(scheme, chapterLevel(entries, parts))
+	labels := make([]string, len(entries))
+	next := 0
+	for i, e := range entries {
+		for ; next < len(directives) && directives[next].line < e.line; next++ {
+			if directives[next].name == directiveAppendix {
+				numbers.startAppendix()
+			}
+		}
+		if !parts[i] {
+			labels[i] = numbers.next(e.level)
+		}
+	}
+	return labels
+}
+
+// chapterLevel returns the shallowest level among entries that are not part
+// headings, or 0 when there are none.
+func chapterLevel(entries []outlineEntry, parts map[int]bool) int {
+	level := 0
+	for i, e := range entries {
+		if !parts[i] && (level == 0 || e.level < level) {
+			level = e.level
+		}
+	}
+	return level
+}
+
+// partEntries reports which entries are part headings: the heading right
+// after each part directive. A part directive whose heading is not among
+// entries is ignored.
+func partEntries(entries []outlineEntry, directives []directive) map[int]bool {
+	headings := map[int]bool{}
+	for _, d := range directives {
+		if d.name == directivePart && d.heading > 0 {
+			headings[d.heading] = true
+		}
+	}
+	parts := map[int]bool{}
+	for i, e := range entries {
+		if headings[e.line] {
+			parts[i] = true
+		}
+	}
+	return parts
+}
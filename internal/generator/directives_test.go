@@ -0,0 +1,90 @@
+package generator
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestGenerateNumberedFileAppendix(t *testing.T) {
+	content := "# Intro\n\n## Scope\n\n# Design\n\n<!-- gtoc:appendix -->\n\n# Glossary\n\n## Terms\n\n# References\n"
+	path := writeNumberingFile(t, content)
+
+	gen := NewGenerator(path, 0, nil)
+	got, err := gen.GenerateNumberedFile()
+	if err != nil {
+		t.Fatalf("GenerateNumberedFile failed: %v", err)
+	}
+
+	for _, h := range []string{"# 1. Intro\n", "## 1.1. Scope\n", "# 2. Design\n", "# A. Glossary\n", "## A.1. Terms\n", "# B. References\n"} {
+		if !strings.Contains(got, h) {
+			t.Errorf("body should contain heading %q, got:\n%s", h, got)
+		}
+	}
+	if !strings.Contains(got, "[A.1. Terms](#a1-terms)<br>") {
+		t.Errorf("TOC should link to the appendix heading, got:\n%s", got)
+	}
+
+	if err := os.WriteFile(path, []byte(got), 0644); err != nil {
+		t.Fatalf("failed to write first result: %v", err)
+	}
+	again, err := gen.GenerateNumberedFile()
+	if err != nil {
+		t.Fatalf("second run failed: %v", err)
+	}
+	if again != got {
+		t.Errorf("appendix numbering should be idempotent:\nfirst:\n%s\nsecond:\n%s", got, again)
+	}
+}
+
+func TestGenerateUnnumberedFileAppendix(t *testing.T) {
+	content := "# 1. Intro\n\n# 2. Design\n\n<!-- gtoc:appendix -->\n\n# A. Glossary\n\n## A.1. Terms\n\n# B. References\n"
+	path := writeNumberingFile(t, content)
+
+	stripped, err := NewGenerator(path, 0, nil).GenerateUnnumberedFile()
+	if err != nil {
+		t.Fatalf("GenerateUnnumberedFile failed: %v", err)
+	}
+	for _, h := range []string{"# Glossary\n", "## Terms\n", "# References\n"} {
+		if !strings.Contains(stripped, h) {
+			t.Errorf("stripping should remove appendix numbers, want %q in:\n%s", h, stripped)
+		}
+	}
+}
+
+func TestGenerateFromContentPartsKeepChapterNumbers(t *testing.T) {
+	content := "<!-- gtoc:part -->\n# Part I: Basics\n\n## Setup\n\n## Usage\n\n" +
+		"<!-- gtoc:part -->\n# Part II: Advanced\n\n## Plugins\n"
+	toc := NewGenerator("", 0, nil).GenerateFromContent(content)
+
+	for _, entry := range []string{
+		"[Part I: Basics](#part-i-basics)<br>",
+		"&nbsp;&nbsp;&nbsp;1\\. [Setup](#setup)<br>",
+		"&nbsp;&nbsp;&nbsp;2\\. [Usage](#usage)<br>",
+		"[Part II: Advanced](#part-ii-advanced)<br>",
+		"&nbsp;&nbsp;&nbsp;3\\. [Plugins](#plugins)<br>",
+	} {
+		if !strings.Contains(toc, entry) {
+			t.Errorf("TOC should contain %q, got:\n%s", entry, toc)
+		}
+	}
+}
+
+func TestGenerateFromContentExcludedPartHeading(t *testing.T) {
+	content := "<!-- gtoc:part -->\n# Part I: Basics\n\n# Setup\n\n# Usage\n"
+	toc := NewGenerator("", 0, []string{"Part I"}).GenerateFromContent(content)
+
+	for _, entry := range []string{"1\\. [Setup](#setup)<br>", "2\\. [Usage](#usage)<br>"} {
+		if !strings.Contains(toc, entry) {
+			t.Errorf("an excluded part heading must not turn the next chapter into a part, want %q in:\n%s", entry, toc)
+		}
+	}
+}
+
+func TestFindDirectivesSkipsCodeFences(t *testing.T) {
+	lines := strings.Split("```\n<!-- gtoc:appendix -->\n```\n<!-- gtoc:part -->\n", "\n")
+	got := findDirectives(lines)
+	if len(got) != 1 || got[0].name != directivePart || got[0].line != 4 {
+		t.Errorf("findDirectives = %+v, want only the part directive on line 4", got)
+	}
+}
@@ -0,0 +1,235 @@
+package generator
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// FilterMode selects how include and exclude patterns match headings.
+type FilterMode string
+
+// The supported filter modes.
+const (
+	// FilterSubstring matches headings containing the pattern, ignoring case.
+	// Anchor patterns must match the whole anchor.
+	FilterSubstring FilterMode = "substring"
+	// FilterGlob matches the whole text against a glob where "*" is any run
+	// of characters, "?" any single one, and "[...]" a character class,
+	// ignoring case.
+	FilterGlob FilterMode = "glob"
+	// FilterRegex matches headings containing a match of a regular
+	// expression, ignoring case unless the expression turns it off with
+	// (?-i).
+	FilterRegex FilterMode = "regex"
+)
+
+// levelTargetPattern matches the "h2:" prefix that restricts a pattern to
+// one heading level.
+var levelTargetPattern = regexp.MustCompile(`^[hH]([1-6]):`)
+
+// filterPattern is one parsed include or exclude pattern. A pattern starting
+// with "#" matches the heading's anchor instead of its text.
+type filterPattern struct {
+	level  int
+	anchor bool
+	match  func(string) bool
+}
+
+// HeadingFilter selects the headings listed in the TOC. A heading is left
+// out when it matches an exclude pattern or, when there are include
+// patterns, matches none of them. With subtree matching, a match applies to
+// the heading and every deeper heading below it, so excluding a section
+// drops its subsections and including one keeps them.
+type HeadingFilter struct {
+	include []filterPattern
+	exclude []filterPattern
+	subtree bool
+}
+
+// NewHeadingFilter parses include and exclude patterns in the given mode,
+// applying matches to whole subtrees when subtree is set. Each pattern may
+// start with "h1:" to "h6:" to match only headings of that level, and then
+// with "#" to match the heading's anchor rather than its text, as in
+// "h2:Contributors" or "#faq".
+func NewHeadingFilter(mode FilterMode, include, exclude []string, subtree bool) (HeadingFilter, error) {
+	f := HeadingFilter{subtree: subtree}
+	var err error
+	if f.include, err = parseFilterPatterns(mode, include); err != nil {
+		return HeadingFilter{}, err
+	}
+	if f.exclude, err = parseFilterPatterns(mode, exclude); err != nil {
+		return HeadingFilter{}, err
+	}
+	return f, nil
+}
+
+// WithFilter sets the include and exclude patterns applied to headings, in
+// addition to the exclude patterns given to NewGenerator.
+func WithFilter(f HeadingFilter) Option {
+	return func(g *Generator) {
+		g.filter = f
+	}
+}
+
+// parseFilterPatterns parses every non-empty pattern in specs.
+func parseFilterPatterns(mode FilterMode, specs []string) ([]filterPattern, error) {
+	var out []filterPattern
+	for _, spec := range specs {
+		if strings.TrimSpace(spec) == "" {
+			continue
+		}
+		p, err := parseFilterPattern(mode, spec)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, p)
+	}
+	return out, nil
+}
+
+// parseFilterPattern parses a single pattern with its optional level and
+// anchor targets.
+func parseFilterPattern(mode FilterMode, spec string) (filterPattern, error) {
+	var p filterPattern
+	if m := levelTargetPattern.FindStringSubmatch(spec); m != nil {
+		p.level, _ = strconv.Atoi(m[1])
+		spec = spec[len(m[0]):]
+	}
+	if strings.HasPrefix(spec, "#") {
+		p.anchor = true
+		spec = spec[1:]
+	}
+
+	var err error
+	p.match, err = matcher(mode, spec, p.anchor)
+	if err != nil {
+		return filterPattern{}, err
+	}
+	return p, nil
+}
+
+// matcher returns the match function of pattern in mode.
+func matcher(mode FilterMode, pattern string, anchor bool) (func(string) bool, error) {
+	switch mode {
+	case FilterSubstring, "":
+		lowered := strings.ToLower(pattern)
+		if anchor {
+			return func(s string) bool { return s == lowered }, nil
+		}
+		return func(s string) bool { return strings.Contains(strings.ToLower(s), lowered) }, nil
+	case FilterGlob:
+		re, err := regexp.Compile("(?i)^" + globToRegexp(pattern) + "$")
+		if err != nil {
+			return nil, fmt.Errorf("invalid glob %q: %w", pattern, err)
+		}
+		return re.MatchString, nil
+	case FilterRegex:
+		re, err := regexp.Compile("(?i)" + pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid regular expression %q: %w", pattern, err)
+		}
+		return re.MatchString, nil
+	default:
+		return nil, fmt.Errorf("unknown filter mode %q (use substring, glob or regex)", mode)
+	}
+}
+
+// globToRegexp translates a glob into an unanchored regular expression.
+// Unlike path.Match, "*" also matches "/", which is common in headings such
+// as "CI/CD".
+func globToRegexp(glob string) string {
+	var sb strings.Builder
+	inClass := false
+	for _, r := range glob {
+		switch {
+		case inClass:
+			if r == ']' {
+				inClass = false
+			}
+			sb.WriteRune(r)
+		case r == '*':
+			sb.WriteString(".*")
+		case r == '?':
+			sb.WriteString(".")
+		case r == '[':
+			inClass = true
+			sb.WriteRune(r)
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	return sb.String()
+}
+
+// matches reports whether p matches a heading.
+func (p filterPattern) matches(level int, text, anchor string) bool {
+	if p.level != 0 && p.level != level {
+		return false
+	}
+	if p.anchor {
+		return p.match(anchor)
+	}
+	return p.match(text)
+}
+
+// anyMatch reports whether any of patterns matches a heading.
+func anyMatch(patterns []filterPattern, level int, text, anchor string) bool {
+	for _, p := range patterns {
+		if p.matches(level, text, anchor) {
+			return true
+		}
+	}
+	return false
+}
+
+// filterScope applies a HeadingFilter to the headings of a document in
+// order, remembering the subtree a match opened.
+type filterScope struct {
+	filter     HeadingFilter
+	excludedAt int
+	includedAt int
+}
+
+// scope returns a filterScope for one pass over a document.
+func (f HeadingFilter) scope() *filterScope {
+	return &filterScope{filter: f}
+}
+
+// keep reports whether the next heading of the document is listed.
+func (s *filterScope) keep(level int, text, anchor string) bool {
+	if s.inExcludedSubtree(level) {
+		return false
+	}
+
+	if anyMatch(s.filter.exclude, level, text, anchor) {
+		if s.filter.subtree {
+			s.excludedAt = level
+		}
+		return false
+	}
+	if len(s.filter.include) == 0 || s.includedAt > 0 {
+		return true
+	}
+	if !anyMatch(s.filter.include, level, text, anchor) {
+		return false
+	}
+	if s.filter.subtree {
+		s.includedAt = level
+	}
+	return true
+}
+
+// inExcludedSubtree reports whether a heading at level sits below an
+// excluded subtree heading, closing the open subtrees it leaves.
+func (s *filterScope) inExcludedSubtree(level int) bool {
+	if s.excludedAt > 0 && level > s.excludedAt {
+		return true
+	}
+	s.excludedAt = 0
+	if level <= s.includedAt {
+		s.includedAt = 0
+	}
+	return false
+}
@@ -0,0 +1,136 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+)
+
+const filterTestContent = `# Guide
+
+## API
+
+### Endpoints
+
+## Rapid Prototyping
+
+## Contributors
+
+### Core team
+
+# Contributors
+`
+
+func TestHeadingFilter(t *testing.T) {
+	tests := []struct {
+		name    string
+		mode    FilterMode
+		include []string
+		exclude []string
+		subtree bool
+		want    []string
+	}{
+		{
+			name:    "substring exclusion also hides words containing it",
+			exclude: []string{"api"},
+			want:    []string{"Guide", "Endpoints", "Contributors", "Core team", "Contributors"},
+		},
+		{
+			name:    "regex exclusion matches whole words",
+			mode:    FilterRegex,
+			exclude: []string{`^api$`},
+			want:    []string{"Guide", "Endpoints", "Rapid Prototyping", "Contributors", "Core team", "Contributors"},
+		},
+		{
+			name:    "glob exclusion",
+			mode:    FilterGlob,
+			exclude: []string{"r*ing", "core ?eam"},
+			want:    []string{"Guide", "API", "Endpoints", "Contributors", "Contributors"},
+		},
+		{
+			name:    "level target",
+			exclude: []string{"h2:Contributors"},
+			want:    []string{"Guide", "API", "Endpoints", "Rapid Prototyping", "Core team", "Contributors"},
+		},
+		{
+			name:    "anchor target",
+			exclude: []string{"#contributors-1"},
+			want:    []string{"Guide", "API", "Endpoints", "Rapid Prototyping", "Contributors", "Core team"},
+		},
+		{
+			name:    "excluded subtree",
+			mode:    FilterRegex,
+			exclude: []string{`^api$`, "h2:contributors"},
+			subtree: true,
+			want:    []string{"Guide", "Rapid Prototyping", "Contributors"},
+		},
+		{
+			name:    "include allowlist",
+			mode:    FilterGlob,
+			include: []string{"guide", "h2:api"},
+			want:    []string{"Guide", "API"},
+		},
+		{
+			name:    "included subtree",
+			mode:    FilterGlob,
+			include: []string{"h2:api", "h2:contributors"},
+			subtree: true,
+			want:    []string{"API", "Endpoints", "Contributors", "Core team"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter, err := NewHeadingFilter(tt.mode, tt.include, tt.exclude, tt.subtree)
+			if err != nil {
+				t.Fatalf("NewHeadingFilter failed: %v", err)
+			}
+
+			var got []string
+			for _, h := range NewGenerator("", 0, nil, WithFilter(filter)).ParseHeadings(filterTestContent) {
+				got = append(got, h.Text)
+			}
+			if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+				t.Errorf("headings = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHeadingFilterKeepsAnchorsOfFilteredHeadings(t *testing.T) {
+	filter, err := NewHeadingFilter(FilterSubstring, []string{"h1:contributors"}, nil, false)
+	if err != nil {
+		t.Fatalf("NewHeadingFilter failed: %v", err)
+	}
+
+	headings := NewGenerator("", 0, nil, WithFilter(filter)).ParseHeadings(filterTestContent)
+	if len(headings) != 1 || headings[0].Anchor != "contributors-1" {
+		t.Errorf("the included heading should keep its document anchor, got %+v", headings)
+	}
+}
+
+func TestNewHeadingFilterRejectsInvalidPatterns(t *testing.T) {
+	if _, err := NewHeadingFilter(FilterRegex, nil, []string{"("}, false); err == nil {
+		t.Error("expected an error for an invalid regular expression")
+	}
+	if _, err := NewHeadingFilter("fuzzy", nil, []string{"api"}, false); err == nil {
+		t.Error("expected an error for an unknown filter mode")
+	}
+}
+
+func TestGenerateNumberedFileHonorsHeadingFilter(t *testing.T) {
+	path := writeNumberingFile(t, filterTestContent)
+	filter, err := NewHeadingFilter(FilterSubstring, nil, []string{"h2:contributors"}, true)
+	if err != nil {
+		t.Fatalf("NewHeadingFilter failed: %v", err)
+	}
+
+	got, err := NewGenerator(path, 0, nil, WithFilter(filter)).GenerateNumberedFile()
+	if err != nil {
+		t.Fatalf("GenerateNumberedFile failed: %v", err)
+	}
+	for _, want := range []string{"## Contributors\n", "### Core team\n", "# 2. Contributors\n"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("result should contain %q, got:\n%s", want, got)
+		}
+	}
+}
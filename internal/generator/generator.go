@@ -2,34 +2,78 @@ package generator
 
 import (
 	"fmt"
-	"os"
 	"regexp"
 	"strings"
+	"unicode"
+
+	"github.com/lpsm-dev/gtoc/internal/fsutil"
+	"github.com/lpsm-dev/gtoc/internal/i18n"
+	"golang.org/x/text/cases"
+	"golang.org/x/text/language"
 )
 
 const (
 	tocStartMarker = "<!-- START_TABLE_OF_CONTENTS -->"
 	tocEndMarker   = "<!-- END_TABLE_OF_CONTENTS -->"
-	backToTopLink  = "<p align=\"right\">(<a href=\"#readme-top\">back to top</a>)</p>"
 )
 
 // headingPattern matches ATX-style markdown headings (# through ######).
 var headingPattern = regexp.MustCompile(`^(#{1,6})\s+(.+)$`)
 
+// closingSequencePattern matches the optional closing sequence of an ATX
+// heading: a run of "#" preceded by whitespace at the end of the line, as in
+// "## Install ##".
+var closingSequencePattern = regexp.MustCompile(`\s+#+\s*$`)
+
 // fenceOpenPattern matches the leading run of backticks or tildes that opens
 // or closes a fenced code block, optionally followed by an info string.
 var fenceOpenPattern = regexp.MustCompile("^(`{3,}|~{3,})")
 
 // disallowedAnchorChars matches every rune that GitHub strips out of a
 // heading before slugifying it: anything that is not a unicode letter,
-// unicode number, space, hyphen, or underscore.
-var disallowedAnchorChars = regexp.MustCompile(`[^\p{L}\p{N} _-]`)
+// combining mark, number, connector punctuation (such as "_"), space, or
+// hyphen. Emoji are symbols and therefore stripped, but the variation
+// selectors that often follow them are marks and survive.
+var disallowedAnchorChars = regexp.MustCompile(`[^\p{L}\p{M}\p{N}\p{Pc} -]`)
 
 // Generator handles the TOC generation for markdown files.
 type Generator struct {
 	targetFile      string
 	maxDepth        int
 	excludePatterns []string
+	writeOptions    fsutil.Options
+	numbering       NumberingScheme
+	messages        i18n.Messages
+	slug            Slugger
+	maxLabelLength  int
+	filter          HeadingFilter
+	fingerprint     string
+}
+
+// Option configures optional Generator behavior.
+type Option func(*Generator)
+
+// WithWriteOptions sets how UpdateFile replaces the target file.
+func WithWriteOptions(opts fsutil.Options) Option {
+	return func(g *Generator) {
+		g.writeOptions = opts
+	}
+}
+
+// WithNumbering sets how outline numbers are written in the TOC and by
+// GenerateNumberedFile and which heading levels receive one.
+func WithNumbering(scheme NumberingScheme) Option {
+	return func(g *Generator) {
+		g.numbering = scheme
+	}
+}
+
+// WithMessages sets the localized strings written with the TOC, such as
+// the back-to-top link text.
+func WithMessages(messages i18n.Messages) Option {
+	return func(g *Generator) {
+		g.messages = messages
+	}
 }
 
 // Heading represents a markdown heading discovered in the document.
@@ -41,69 +85,76 @@ type Heading struct {
 }
 
 // NewGenerator creates a new Generator.
-func NewGenerator(targetFile string, maxDepth int, excludePatterns []string) *Generator {
-	return &Generator{
+func NewGenerator(targetFile string, maxDepth int, excludePatterns []string, opts ...Option) *Generator {
+	g := &Generator{
 		targetFile:      targetFile,
 		maxDepth:        maxDepth,
 		excludePatterns: excludePatterns,
+		messages:        i18n.Default(),
+		slug:            createAnchor,
+	}
+	for _, opt := range opts {
+		opt(g)
 	}
+	return g
 }
 
 // Generate creates a markdown table of contents from the target file's headings.
 func (g *Generator) Generate() (string, error) {
-	headings, err := g.extractHeadings()
+	content, err := g.readTarget()
 	if err != nil {
 		return "", err
 	}
+	return g.GenerateFromContent(content), nil
+}
 
+// GenerateFromContent creates a markdown table of contents from the headings
+// in content, which is useful for callers such as editors that hold unsaved
+// buffers rather than files on disk.
+func (g *Generator) GenerateFromContent(content string) string {
+	headings := g.ParseHeadings(content)
 	minLevel := minHeadingLevel(headings)
 
+	directives := findDirectives(strings.Split(content, "\n"))
+	entries := make([]outlineEntry, len(headings))
+	lines := make([]int, len(headings))
+	texts := make([]string, len(headings))
+	for i, h := range headings {
+		entries[i] = outlineEntry{line: h.Line, level: h.Level}
+		lines[i], texts[i] = h.Line, h.Text
+	}
+	labels := numberOutline(g.numbering, entries, directives)
+
 	var sb strings.Builder
 	sb.WriteString(tocStartMarker + "\n\n")
-	sb.WriteString(renderEntries(headings, minLevel))
-	sb.WriteString("\n" + backToTopLink + "\n")
+	sb.WriteString(renderEntries(headings, minLevel, labels, g.tocTexts(lines, texts, directives)))
+	sb.WriteString("\n" + i18n.BackToTopLink(g.messages.BackToTop) + "\n")
 	sb.WriteString("\n" + tocEndMarker)
-	return sb.String(), nil
+	return sb.String()
 }
 
 // renderEntries renders the TOC as a hierarchical dotted outline: every
 // heading carries its full path number (# -> 1, ## -> 1.1, ### -> 1.1.1),
 // reflecting the heading depth. GitHub markdown has no native "1.1." list
-// marker, so entries are emitted as escaped literal text - the leading dot is
+// marker, so entries are emitted as escaped literal text - the first dot is
 // backslash-escaped so the line is not parsed as an ordered list - indented
 // with &nbsp; per level and separated with <br> so each entry keeps its own
-// line in a rendered README.
-func renderEntries(headings []*Heading, minLevel int) string {
+// line in a rendered README. labels holds the outline number of each
+// heading; headings with no number are listed without one. texts holds the
+// link text of each entry.
+func renderEntries(headings []*Heading, minLevel int, labels, texts []string) string {
 	var sb strings.Builder
-	var counters []int
-	for _, heading := range headings {
-		rel := heading.Level - minLevel
-		if rel < len(counters) {
-			counters = counters[:rel+1]
-		} else {
-			for len(counters) <= rel {
-				counters = append(counters, 0)
-			}
+	for i, heading := range headings {
+		indent := strings.Repeat("&nbsp;", 3*(heading.Level-minLevel))
+		marker := ""
+		if labels[i] != "" {
+			marker = strings.Replace(labels[i], ".", `\.`, 1) + " "
 		}
-		counters[rel]++
-
-		marker := strings.Replace(dottedNumber(counters)+".", ".", `\.`, 1)
-		indent := strings.Repeat("&nbsp;", 3*rel)
-		sb.WriteString(fmt.Sprintf("%s%s [%s](#%s)<br>\n", indent, marker, heading.Text, heading.Anchor))
+		sb.WriteString(fmt.Sprintf("%s%s[%s](#%s)<br>\n", indent, marker, texts[i], heading.Anchor))
 	}
 	return sb.String()
 }
 
-// dottedNumber joins the per-level counters into a dotted path such as
-// "1", "1.2", or "1.2.1".
-func dottedNumber(counters []int) string {
-	parts := make([]string, len(counters))
-	for i, c := range counters {
-		parts[i] = fmt.Sprintf("%d", c)
-	}
-	return strings.Join(parts, ".")
-}
-
 // existingNumberPrefix matches a leading outline number ("1. ", "1.2. ", ...)
 // so re-numbering headings is idempotent.
 var existingNumberPrefix = regexp.MustCompile(`^\d+(\.\d+)*\.\s+`)
@@ -120,84 +171,238 @@ type headingLine struct {
 // hierarchical outline number (# -> "1.", ## -> "1.1.", ### -> "1.1.1.") and
 // returns the full updated file content, including a refreshed TOC that links
 // to the numbered headings. Numbering is idempotent: an existing number on a
-// heading is stripped and recomputed.
+// heading is stripped and recomputed. Headings outside the numbering scheme's
+// level range are left unnumbered, and appendix and part directives are
+// honored (see numberOutline).
 func (g *Generator) GenerateNumberedFile() (string, error) {
-	raw, err := os.ReadFile(g.targetFile)
+	raw, err := g.readTarget()
 	if err != nil {
 		return "", err
 	}
-	lines := strings.Split(string(raw), "\n")
+	return g.GenerateNumberedContent(raw), nil
+}
+
+// GenerateNumberedContent is GenerateNumberedFile for content held in
+// memory rather than read from the target file.
+func (g *Generator) GenerateNumberedContent(content string) string {
+	lines := strings.Split(content, "\n")
 
-	found := g.collectHeadingLines(lines)
+	directives := findDirectives(lines)
+	found := g.collectHeadingLines(lines, directives)
 	minLevel := 1
+	entries := make([]outlineEntry, len(found))
+	headingLines := make([]int, len(found))
+	cleanTexts := make([]string, len(found))
 	for i, h := range found {
 		if i == 0 || h.level < minLevel {
 			minLevel = h.level
 		}
+		entries[i] = outlineEntry{line: h.index + 1, level: h.level}
+		headingLines[i], cleanTexts[i] = h.index+1, h.text
 	}
+	labels := numberOutline(g.numbering, entries, directives)
+	entryTexts := g.tocTexts(headingLines, cleanTexts, directives)
 
-	anchorCounts := map[string]int{}
-	var counters []int
-	headings := make([]*Heading, 0, len(found))
-	for _, h := range found {
-		rel := h.level - minLevel
-		if rel < len(counters) {
-			counters = counters[:rel+1]
-		} else {
-			for len(counters) <= rel {
-				counters = append(counters, 0)
-			}
+	texts := make([]string, len(found))
+	for i, h := range found {
+		texts[i] = h.text
+		if labels[i] != "" {
+			texts[i] = labels[i] + " " + h.text
+			entryTexts[i] = labels[i] + " " + entryTexts[i]
 		}
-		counters[rel]++
+		lines[h.index] = strings.Repeat("#", h.level) + " " + texts[i]
+	}
 
-		numberedText := dottedNumber(counters) + ". " + h.text
-		anchor := uniqueAnchor(createAnchor(numberedText), anchorCounts)
-		lines[h.index] = strings.Repeat("#", h.level) + " " + numberedText
-		headings = append(headings, &Heading{Level: h.level, Text: numberedText, Anchor: anchor})
+	anchors := g.headingAnchors(lines)
+	headings := make([]*Heading, 0, len(found))
+	for i, h := range found {
+		headings = append(headings, &Heading{Level: h.level, Text: texts[i], Anchor: anchors[h.index]})
 	}
 
 	numbered := strings.Join(lines, "\n")
-	return g.GetFileWithUpdatedTOC(numbered, buildNumberedTOC(headings, minLevel)), nil
+	return g.GetFileWithUpdatedTOC(numbered, g.buildNumberedTOC(headings, minLevel, entryTexts))
 }
 
 // collectHeadingLines returns every heading line eligible for numbering,
 // skipping fenced code blocks and existing TOC blocks and stripping any
 // existing outline number so re-runs stay stable.
-func (g *Generator) collectHeadingLines(lines []string) []headingLine {
+func (g *Generator) collectHeadingLines(lines []string, directives []directive) []headingLine {
+	return g.eligibleHeadings(g.stripOutlineNumbers(allHeadingLines(lines), directives))
+}
+
+// allHeadingLines returns every heading in lines with its trimmed text,
+// skipping fenced code blocks and existing TOC blocks.
+func allHeadingLines(lines []string) []headingLine {
 	var out []headingLine
 	filter := &lineFilter{}
 	for i, line := range lines {
 		if filter.skip(line) {
 			continue
 		}
-		matches := headingPattern.FindStringSubmatch(line)
-		if len(matches) <= 2 {
-			continue
+		if matches := headingPattern.FindStringSubmatch(line); len(matches) > 2 {
+			out = append(out, headingLine{index: i, level: len(matches[1]), text: headingText(matches[2])})
 		}
-		level := len(matches[1])
-		if g.maxDepth > 0 && level > g.maxDepth {
+	}
+	return out
+}
+
+// eligibleHeadings returns the headings that the depth limit and the
+// heading filters keep. Anchor patterns see the anchor GitHub gives each
+// heading, duplicate suffix included, so "#faq-1" matches the second FAQ.
+func (g *Generator) eligibleHeadings(headings []headingLine) []headingLine {
+	var out []headingLine
+	scope := g.filter.scope()
+	counts := map[string]int{}
+	for _, h := range headings {
+		anchor := uniqueAnchor(g.slug(h.text), counts)
+		if g.maxDepth > 0 && h.level > g.maxDepth {
 			continue
 		}
-		text := existingNumberPrefix.ReplaceAllString(strings.TrimSpace(matches[2]), "")
-		if g.isExcluded(text) {
+		if !scope.keep(h.level, h.text, anchor) || g.isExcluded(h.text) {
 			continue
 		}
-		out = append(out, headingLine{index: i, level: level, text: text})
+		out = append(out, h)
 	}
 	return out
 }
 
+// stripOutlineNumbers returns headings with their outline number removed.
+// A number made of digits alone is always removed, but one with letters,
+// such as "I.a." or an appendix's "A.1.", only when it is the number the
+// scheme gives the heading: lowercase letters and roman numerals also start
+// prose like "e.g. Foo" or "a. note", which must be kept.
+func (g *Generator) stripOutlineNumbers(headings []headingLine, directives []directive) []headingLine {
+	strip := g.numberStripper(directives)
+	stripped := make([]headingLine, len(headings))
+	for i, h := range headings {
+		stripped[i] = headingLine{index: h.index, level: h.level, text: strip(h.index+1, h.text)}
+	}
+
+	eligible := g.eligibleHeadings(stripped)
+	entries := make([]outlineEntry, len(eligible))
+	for i, h := range eligible {
+		entries[i] = outlineEntry{line: h.index + 1, level: h.level}
+	}
+	expected := map[int]string{}
+	for i, label := range numberOutline(g.numbering, entries, directives) {
+		expected[eligible[i].index] = label
+	}
+
+	for i, h := range headings {
+		prefix := strings.TrimSpace(strings.TrimSuffix(h.text, stripped[i].text))
+		if strings.ContainsFunc(prefix, unicode.IsLetter) && prefix != expected[h.index] {
+			stripped[i].text = h.text
+		}
+	}
+	return stripped
+}
+
+// headingAnchors returns the anchor of every heading in lines, keyed by line
+// index. Headings the generator's filters leave out still take part in
+// duplicate numbering, as they do on GitHub.
+func (g *Generator) headingAnchors(lines []string) map[int]string {
+	anchors := map[int]string{}
+	counts := map[string]int{}
+	filter := &lineFilter{}
+	for i, line := range lines {
+		if filter.skip(line) {
+			continue
+		}
+		if matches := headingPattern.FindStringSubmatch(line); len(matches) > 2 {
+			anchors[i] = uniqueAnchor(g.slug(headingText(matches[2])), counts)
+		}
+	}
+	return anchors
+}
+
+// GenerateUnnumberedFile is the inverse of GenerateNumberedFile: it strips
+// the outline number from every heading, rewrites in-document links that
+// pointed at the numbered anchors so they follow the renamed headings, and
+// returns the full updated file content with a refreshed TOC. Stripping is
+// applied to every heading regardless of depth or exclusion filters, since a
+// number left on a filtered heading would be just as stale.
+func (g *Generator) GenerateUnnumberedFile() (string, error) {
+	raw, err := g.readTarget()
+	if err != nil {
+		return "", err
+	}
+	return g.GenerateUnnumberedContent(raw), nil
+}
+
+// GenerateUnnumberedContent is GenerateUnnumberedFile for content held in
+// memory rather than read from the target file.
+func (g *Generator) GenerateUnnumberedContent(content string) string {
+	lines := strings.Split(content, "\n")
+
+	oldCounts := map[string]int{}
+	newCounts := map[string]int{}
+	renamed := map[string]string{}
+	headings := allHeadingLines(lines)
+	stripped := g.stripOutlineNumbers(headings, findDirectives(lines))
+	for i, h := range headings {
+		oldAnchor := uniqueAnchor(g.slug(h.text), oldCounts)
+		newAnchor := uniqueAnchor(g.slug(stripped[i].text), newCounts)
+		if stripped[i].text != h.text {
+			lines[h.index] = strings.Repeat("#", h.level) + " " + stripped[i].text
+		}
+		if oldAnchor != newAnchor {
+			renamed[oldAnchor] = newAnchor
+		}
+	}
+
+	unnumbered := rewriteAnchorLinks(strings.Join(lines, "\n"), renamed)
+	return g.GetFileWithUpdatedTOC(unnumbered, g.GenerateFromContent(unnumbered))
+}
+
+// numberStripper returns a function that removes anything shaped like an
+// outline number of the scheme from the text of the heading on a given line,
+// accepting letter numbers on headings after an appendix directive.
+func (g *Generator) numberStripper(directives []directive) func(line int, text string) string {
+	appendixLine := firstDirectiveLine(directives, directiveAppendix)
+	main := g.numbering.prefixPattern(false)
+	appendix := g.numbering.prefixPattern(true)
+	return func(line int, text string) string {
+		if appendixLine > 0 && line > appendixLine {
+			return appendix.ReplaceAllString(text, "")
+		}
+		return main.ReplaceAllString(text, "")
+	}
+}
+
+// rewriteAnchorLinks replaces every in-document link to an anchor listed in
+// renamed with a link to its new anchor, leaving all other bytes unchanged.
+func rewriteAnchorLinks(content string, renamed map[string]string) string {
+	if len(renamed) == 0 {
+		return content
+	}
+
+	lines := strings.Split(content, "\n")
+	links := FindAnchorLinks(content)
+	// Walk backwards so earlier offsets on the same line stay valid.
+	for i := len(links) - 1; i >= 0; i-- {
+		link := links[i]
+		target, ok := renamed[link.Anchor]
+		if !ok {
+			continue
+		}
+		line := lines[link.Line-1]
+		lines[link.Line-1] = line[:link.Start] + target + line[link.End:]
+	}
+	return strings.Join(lines, "\n")
+}
+
 // buildNumberedTOC lists already-numbered headings. Because the number is part
 // of each heading (and thus the link text), entries are plain links indented
-// with &nbsp; per level and broken with <br> - no escaping is needed.
-func buildNumberedTOC(headings []*Heading, minLevel int) string {
+// with &nbsp; per level and broken with <br> - no escaping is needed. texts
+// holds the link text of each entry, number included.
+func (g *Generator) buildNumberedTOC(headings []*Heading, minLevel int, texts []string) string {
 	var sb strings.Builder
 	sb.WriteString(tocStartMarker + "\n\n")
-	for _, h := range headings {
+	for i, h := range headings {
 		indent := strings.Repeat("&nbsp;", 3*(h.Level-minLevel))
-		sb.WriteString(fmt.Sprintf("%s[%s](#%s)<br>\n", indent, h.Text, h.Anchor))
+		sb.WriteString(fmt.Sprintf("%s[%s](#%s)<br>\n", indent, texts[i], h.Anchor))
 	}
-	sb.WriteString("\n" + backToTopLink + "\n")
+	sb.WriteString("\n" + i18n.BackToTopLink(g.messages.BackToTop) + "\n")
 	sb.WriteString("\n" + tocEndMarker)
 	return sb.String()
 }
@@ -271,58 +476,66 @@ func fenceMarker(line string) string {
 	return match[:1]
 }
 
-// extractHeadings parses the target markdown file and returns its headings,
-// skipping fenced code blocks and any existing TOC block.
-func (g *Generator) extractHeadings() ([]*Heading, error) {
-	content, err := os.ReadFile(g.targetFile)
-	if err != nil {
-		return nil, err
-	}
-
+// ParseHeadings returns the headings found in content, applying the
+// generator's depth and exclusion filters and skipping fenced code blocks
+// and any existing TOC block.
+func (g *Generator) ParseHeadings(content string) []*Heading {
 	headings := []*Heading{}
 	anchorCounts := map[string]int{}
 	filter := &lineFilter{}
+	scope := g.filter.scope()
 
-	for i, line := range strings.Split(string(content), "\n") {
+	for i, line := range strings.Split(content, "\n") {
 		if filter.skip(line) {
 			continue
 		}
 
-		if heading := g.parseHeadingLine(line, i+1, anchorCounts); heading != nil {
+		if heading := g.parseHeadingLine(line, i+1, anchorCounts, scope); heading != nil {
 			headings = append(headings, heading)
 		}
 	}
 
-	return headings, nil
+	return headings
 }
 
 // parseHeadingLine attempts to parse a single line as a markdown heading,
-// applying depth filtering, exclusion patterns, and anchor deduplication.
-// It returns nil when the line is not a qualifying heading.
-func (g *Generator) parseHeadingLine(line string, lineNum int, anchorCounts map[string]int) *Heading {
+// applying anchor deduplication, depth filtering, exclusion patterns, and
+// the heading filter. It returns nil when the line is not a qualifying
+// heading.
+func (g *Generator) parseHeadingLine(line string, lineNum int, anchorCounts map[string]int, scope *filterScope) *Heading {
 	matches := headingPattern.FindStringSubmatch(line)
 	if len(matches) <= 2 {
 		return nil
 	}
 
 	level := len(matches[1])
+
+	// Every heading claims its anchor, filtered or not, so that duplicates
+	// get the same suffix GitHub gives them.
+	text := headingText(matches[2])
+	anchor := uniqueAnchor(g.slug(text), anchorCounts)
 	if g.maxDepth > 0 && level > g.maxDepth {
 		return nil
 	}
-
-	text := strings.TrimSpace(matches[2])
-	if g.isExcluded(text) {
+	if !scope.keep(level, text, anchor) || g.isExcluded(text) {
 		return nil
 	}
 
 	return &Heading{
 		Level:  level,
 		Text:   text,
-		Anchor: uniqueAnchor(createAnchor(text), anchorCounts),
+		Anchor: anchor,
 		Line:   lineNum,
 	}
 }
 
+// headingText returns the text of an ATX heading from the part of the line
+// after its opening hashes, without surrounding whitespace or a closing
+// sequence.
+func headingText(raw string) string {
+	return strings.TrimSpace(closingSequencePattern.ReplaceAllString(raw, ""))
+}
+
 // isExcluded reports whether heading text matches any exclude pattern via a
 // case-insensitive substring match. A nil or empty pattern list excludes nothing.
 func (g *Generator) isExcluded(text string) bool {
@@ -340,43 +553,101 @@ func (g *Generator) isExcluded(text string) bool {
 
 // uniqueAnchor returns slug, or slug with a "-1", "-2", ... suffix if it has
 // already been used earlier in the same Generate() call, matching GitHub's
-// duplicate-heading anchor behavior. counts is mutated in place.
+// duplicate-heading anchor behavior. A suffixed anchor is itself reserved, so
+// a later heading whose own slug is "setup-1" becomes "setup-1-1" rather than
+// colliding. counts is mutated in place.
 func uniqueAnchor(slug string, counts map[string]int) string {
-	count := counts[slug]
-	counts[slug] = count + 1
-	if count == 0 {
-		return slug
+	anchor := slug
+	for {
+		if _, taken := counts[anchor]; !taken {
+			break
+		}
+		counts[slug]++
+		anchor = fmt.Sprintf("%s-%d", slug, counts[slug])
 	}
-	return fmt.Sprintf("%s-%d", slug, count)
+	counts[anchor] = 0
+	return anchor
 }
 
 // createAnchor generates a GitHub-compatible anchor slug from heading text,
-// matching the behavior of github-slugger: lowercase, strip everything that
-// isn't a unicode letter, unicode number, space, hyphen, or underscore, then
-// turn each space into a hyphen. Consecutive hyphens are not collapsed and
-// leading/trailing hyphens are not trimmed, since GitHub does neither.
+// matching the behavior of github-slugger on the rendered heading: reduce
+// the markdown to its plain text, lowercase it with full unicode case
+// mapping, strip every disallowed rune, then turn each space into a hyphen.
+// Consecutive hyphens are not collapsed and leading/trailing hyphens are not
+// trimmed, since GitHub does neither. The text is not unicode-normalized:
+// GitHub keeps decomposed accents as combining marks.
 func createAnchor(text string) string {
-	trimmed := strings.TrimSpace(text)
-	lowered := strings.ToLower(trimmed)
-	stripped := disallowedAnchorChars.ReplaceAllString(lowered, "")
+	return slugify(cases.Lower(language.Und).String(plainText(text)))
+}
+
+// slugify strips disallowed runes from already lowercased text and turns
+// each space into a hyphen.
+func slugify(lowered string) string {
+	stripped := disallowedAnchorChars.ReplaceAllString(strings.TrimSpace(lowered), "")
 	return strings.ReplaceAll(stripped, " ", "-")
 }
 
-// UpdateFile writes the file with the TOC replaced or prepended, preserving
-// the original file's permission bits.
+// UpdateFile writes the file with the TOC replaced or prepended. The write
+// is atomic and keeps the file's permission bits, line endings, and BOM. If
+// the file changed since Generate read it, UpdateFile fails with
+// fsutil.ErrModified instead of overwriting the change.
 func (g *Generator) UpdateFile(toc string) error {
-	content, err := os.ReadFile(g.targetFile)
+	snap, err := fsutil.ReadSnapshot(g.targetFile)
 	if err != nil {
 		return err
 	}
+	if g.fingerprint != "" && snap.Fingerprint != g.fingerprint {
+		return fmt.Errorf("%w: %s", fsutil.ErrModified, g.targetFile)
+	}
 
-	mode := os.FileMode(0644)
-	if info, statErr := os.Stat(g.targetFile); statErr == nil {
-		mode = info.Mode().Perm()
+	newContent := g.GetFileWithUpdatedTOC(snap.Text, toc)
+	return g.write(newContent, snap.Fingerprint)
+}
+
+// WriteFile replaces the target file with content produced by a previous
+// call such as GenerateNumberedFile, failing with fsutil.ErrModified if the
+// file changed since it was read.
+func (g *Generator) WriteFile(content string) error {
+	return g.write(content, g.fingerprint)
+}
+
+// write writes content to the target file, expecting its on-disk content to
+// still match the given fingerprint.
+func (g *Generator) write(content, expect string) error {
+	opts := g.writeOptions
+	opts.Expect = expect
+	return fsutil.WriteText(g.targetFile, content, opts)
+}
+
+// readTarget reads the target file and remembers its fingerprint so a later
+// write can detect concurrent modification.
+func (g *Generator) readTarget() (string, error) {
+	snap, err := fsutil.ReadSnapshot(g.targetFile)
+	if err != nil {
+		return "", err
 	}
+	g.fingerprint = snap.Fingerprint
+	return snap.Text, nil
+}
 
-	newContent := g.GetFileWithUpdatedTOC(string(content), toc)
-	return os.WriteFile(g.targetFile, []byte(newContent), mode)
+// HasTOC reports whether content holds a table of contents block between
+// the start and end markers.
+func HasTOC(content string) bool {
+	start := strings.Index(content, tocStartMarker)
+	return start >= 0 && strings.Index(content, tocEndMarker) > start
+}
+
+// RemoveTOC returns content without its TOC block and the blank line that
+// separates it from the text after it, or content itself when it has none.
+func RemoveTOC(content string) string {
+	start := strings.Index(content, tocStartMarker)
+	end := strings.Index(content, tocEndMarker)
+	if start < 0 || end < start {
+		return content
+	}
+	rest := content[end+len(tocEndMarker):]
+	rest = strings.TrimPrefix(strings.TrimPrefix(rest, "\n"), "\n")
+	return content[:start] + rest
 }
 
 // GetFileWithUpdatedTOC returns the file content with the TOC block replaced
@@ -1,10 +1,14 @@
 package generator
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
+
+	"github.com/lpsm-dev/gtoc/internal/fsutil"
+	"github.com/lpsm-dev/gtoc/internal/i18n"
 )
 
 // writeTempFile creates a markdown file with the given content in a fresh
@@ -54,7 +58,7 @@ Content for the third heading.
 		"&nbsp;&nbsp;&nbsp;1\\.1. [First Sub-heading](#first-sub-heading)<br>\n" +
 		"2\\. [Second Heading](#second-heading)<br>\n" +
 		"3\\. [Third Heading](#third-heading)<br>\n" +
-		"\n" + backToTopLink + "\n" +
+		"\n" + i18n.BackToTopLink("back to top") + "\n" +
 		"\n" + tocEndMarker
 
 	if toc != expected {
@@ -88,6 +92,119 @@ func TestCreateAnchor(t *testing.T) {
 	}
 }
 
+// TestCreateAnchorGitHubCorpus pins heading anchors to the ones GitHub
+// renders for headings that exercise emoji, inline markdown, HTML, closing
+// sequences, and unicode case mapping and normalization.
+func TestCreateAnchorGitHubCorpus(t *testing.T) {
+	tests := []struct {
+		heading string
+		anchor  string
+	}{
+		// Emoji are symbols and stripped, leaving the surrounding spaces.
+		{"🚀 Getting Started", "-getting-started"},
+		{"Features ✨", "features-"},
+		{"👩‍💻 Developers", "-developers"},
+		// A variation selector is a combining mark, so GitHub keeps it.
+		{"⚠️ Warning", "\ufe0f-warning"},
+		// Entities are decoded before punctuation is stripped.
+		{"Q&amp;A", "qa"},
+		{"Tom &amp; Jerry", "tom--jerry"},
+		{"&lt;div&gt; tags", "div-tags"},
+		{"Caf&eacute;", "café"},
+		// Decomposed accents stay decomposed; GitHub does not normalize.
+		{"Cafe\u0301", "cafe\u0301"},
+		{"हिन्दी", "हिन्दी"},
+		// Lowercasing uses full unicode case mapping.
+		{"İstanbul", "i\u0307stanbul"},
+		{"ΣΟΦΟΣ", "σοφος"},
+		{"Über uns", "über-uns"},
+		{"日本語の見出し", "日本語の見出し"},
+		{"１２３", "１２３"},
+		// Connector punctuation survives, other dashes do not.
+		{"a‿b", "a‿b"},
+		{"Non‑breaking", "nonbreaking"},
+		{"C++ & C#", "c--c"},
+		// Anchors come from the rendered text, not the markdown source.
+		{"`go test ./...`", "go-test-"},
+		{"`snake_case` config", "snake_case-config"},
+		{"Heading with `&amp;` code", "heading-with-amp-code"},
+		{"_Emphasis_ here", "emphasis-here"},
+		{"__Strong__ and _em_", "strong-and-em"},
+		{"snake_case_name", "snake_case_name"},
+		{`\_literal\_`, "_literal_"},
+		{"[Link text](https://example.com)", "link-text"},
+		{"Logo ![badge](badge.svg) Title", "logo--title"},
+		{"<code>inline</code> HTML", "inline-html"},
+		{"Autolink <https://example.com>", "autolink-httpsexamplecom"},
+		// A closing sequence is not part of the heading; a "#" that is not
+		// preceded by a space is.
+		{"Install ##", "install"},
+		{"Install #####   ", "install"},
+		{"C#", "c"},
+		{`Escaped \#`, "escaped-"},
+	}
+
+	gen := NewGenerator("", 0, nil)
+	for _, tt := range tests {
+		t.Run(tt.heading, func(t *testing.T) {
+			headings := gen.ParseHeadings("## " + tt.heading + "\n")
+			if len(headings) != 1 {
+				t.Fatalf("ParseHeadings(%q) returned %d headings, want 1", tt.heading, len(headings))
+			}
+			if got := headings[0].Anchor; got != tt.anchor {
+				t.Errorf("anchor of %q = %q, want %q", tt.heading, got, tt.anchor)
+			}
+		})
+	}
+}
+
+func TestGenerateFromContentStripsClosingSequences(t *testing.T) {
+	toc := NewGenerator("", 0, nil).GenerateFromContent("# Guide #\n\n## Install ##\n")
+	for _, entry := range []string{"[Guide](#guide)", "[Install](#install)"} {
+		if !strings.Contains(toc, entry) {
+			t.Errorf("TOC should contain %q, got:\n%s", entry, toc)
+		}
+	}
+}
+
+func TestUniqueAnchorReservesSuffixedAnchors(t *testing.T) {
+	tests := []struct {
+		name  string
+		slugs []string
+		want  []string
+	}{
+		{"repeated", []string{"setup", "setup", "setup"}, []string{"setup", "setup-1", "setup-2"}},
+		{"suffix taken later", []string{"setup", "setup", "setup-1"}, []string{"setup", "setup-1", "setup-1-1"}},
+		{"suffix taken first", []string{"setup-1", "setup", "setup"}, []string{"setup-1", "setup", "setup-2"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			counts := map[string]int{}
+			for i, slug := range tt.slugs {
+				if got := uniqueAnchor(slug, counts); got != tt.want[i] {
+					t.Errorf("anchor %d = %q, want %q", i, got, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestGenerateCountsFilteredHeadingsInAnchors(t *testing.T) {
+	content := "# Guide\n\n### Setup\n\n# Internal\n\n## Setup\n\n# Setup\n"
+
+	gen := NewGenerator("", 2, []string{"internal"})
+	toc := gen.GenerateFromContent(content)
+	for _, entry := range []string{"[Setup](#setup-1)", "[Setup](#setup-2)"} {
+		if !strings.Contains(toc, entry) {
+			t.Errorf("TOC should contain %q, got:\n%s", entry, toc)
+		}
+	}
+	if strings.Contains(toc, "(#setup)") {
+		t.Errorf("the depth-filtered heading owns #setup, got:\n%s", toc)
+	}
+}
+
 func TestGenerateDuplicateHeadings(t *testing.T) {
 	content := `# Setup
 First setup section.
@@ -176,7 +293,7 @@ func TestGenerateAndUpdateFileIdempotent(t *testing.T) {
 	original := tocStartMarker + "\n\n" +
 		"- [Old Entry](#old-entry)\n" +
 		"# Fake Heading Inside TOC\n" +
-		"\n" + backToTopLink + "\n" +
+		"\n" + i18n.BackToTopLink("back to top") + "\n" +
 		"\n" + tocEndMarker + "\n\n" +
 		"# Real Heading\n" +
 		"Some content.\n"
@@ -402,3 +519,40 @@ func TestGetFileWithUpdatedTOC(t *testing.T) {
 		}
 	})
 }
+
+func TestRemoveTOCUndoesPrepend(t *testing.T) {
+	gen := NewGenerator("unused.md", 0, nil)
+	toc := tocStartMarker + "\nnew\n" + tocEndMarker
+
+	for _, original := range []string{"# Title\n\ntext\n", "no TOC here\n"} {
+		if got := RemoveTOC(gen.GetFileWithUpdatedTOC(original, toc)); got != original {
+			t.Errorf("RemoveTOC() = %q, want %q", got, original)
+		}
+	}
+	if got := RemoveTOC("intro\n\n" + toc + "\n\n## Usage\n"); got != "intro\n\n## Usage\n" {
+		t.Errorf("RemoveTOC() should drop the blank line after the block, got %q", got)
+	}
+}
+
+func TestUpdateFileDetectsConcurrentModification(t *testing.T) {
+	path := writeTempFile(t, "# First\n\n# Second\n")
+
+	gen := NewGenerator(path, 0, nil)
+	toc, err := gen.Generate()
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+
+	edited := "# First\n\n# Second\n\n# Added by an editor\n"
+	if err := os.WriteFile(path, []byte(edited), 0644); err != nil {
+		t.Fatalf("failed to modify file: %v", err)
+	}
+
+	if err := gen.UpdateFile(toc); !errors.Is(err, fsutil.ErrModified) {
+		t.Fatalf("UpdateFile() error = %v, want fsutil.ErrModified", err)
+	}
+	content, _ := os.ReadFile(path)
+	if string(content) != edited {
+		t.Error("UpdateFile must not overwrite a concurrent edit")
+	}
+}
@@ -0,0 +1,56 @@
+package generator
+
+import (
+	"strconv"
+	"strings"
+)
+
+// directiveLabel replaces the TOC text of the next heading, as in
+// <!-- gtoc:label "Short name" -->. The heading and its anchor are left
+// unchanged.
+const directiveLabel = "label"
+
+// ellipsis ends a label shortened to the maximum label length.
+const ellipsis = "…"
+
+// WithMaxLabelLength shortens TOC entry text longer than n characters,
+// ending it with an ellipsis. Zero or less leaves labels at full length.
+func WithMaxLabelLength(n int) Option {
+	return func(g *Generator) {
+		g.maxLabelLength = n
+	}
+}
+
+// tocTexts returns the TOC text of each heading: its gtoc:label override or
+// its own text, shortened to the maximum label length. lines holds the
+// 1-based line of each heading and texts its heading text.
+func (g *Generator) tocTexts(lines []int, texts []string, directives []directive) []string {
+	overrides := attachDirectives(directives, directiveLabel, lines)
+	out := make([]string, len(texts))
+	for i, text := range texts {
+		if overrides[i] != "" {
+			text = unquoteLabel(overrides[i])
+		}
+		out[i] = truncateLabel(text, g.maxLabelLength)
+	}
+	return out
+}
+
+// unquoteLabel returns the text of a label directive argument, which may be
+// a double-quoted string.
+func unquoteLabel(arg string) string {
+	if unquoted, err := strconv.Unquote(arg); err == nil && strings.HasPrefix(arg, `"`) {
+		return unquoted
+	}
+	return arg
+}
+
+// truncateLabel shortens text to at most max characters, replacing the tail
+// with an ellipsis. A max of zero or less disables shortening.
+func truncateLabel(text string, max int) string {
+	runes := []rune(text)
+	if max <= 0 || len(runes) <= max {
+		return text
+	}
+	return strings.TrimRight(string(runes[:max-1]), " ") + ellipsis
+}
@@ -0,0 +1,74 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTruncateLabel(t *testing.T) {
+	tests := []struct {
+		text string
+		max  int
+		want string
+	}{
+		{"Short", 10, "Short"},
+		{"Exactly ten", 11, "Exactly ten"},
+		{"Getting started quickly", 12, "Getting sta…"},
+		{"Trailing space cut", 10, "Trailing…"},
+		{"Instalação e configuração", 12, "Instalação…"},
+		{"Unlimited length", 0, "Unlimited length"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			if got := truncateLabel(tt.text, tt.max); got != tt.want {
+				t.Errorf("truncateLabel(%q, %d) = %q, want %q", tt.text, tt.max, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGenerateFromContentHonorsLabelDirective(t *testing.T) {
+	content := "# Intro\n\n<!-- gtoc:label \"Setup\" -->\n# How to set up the project on a new machine\n\n" +
+		"<!-- gtoc:label Usage -->\n## Using it every day\n"
+
+	toc := NewGenerator("", 0, nil).GenerateFromContent(content)
+	for _, entry := range []string{
+		"1\\. [Intro](#intro)<br>",
+		"2\\. [Setup](#how-to-set-up-the-project-on-a-new-machine)<br>",
+		"&nbsp;&nbsp;&nbsp;2\\.1. [Usage](#using-it-every-day)<br>",
+	} {
+		if !strings.Contains(toc, entry) {
+			t.Errorf("TOC should contain %q, got:\n%s", entry, toc)
+		}
+	}
+}
+
+func TestGenerateFromContentDropsLabelsOfExcludedHeadings(t *testing.T) {
+	content := "# Intro\n\n<!-- gtoc:label \"Short\" -->\n## Internal notes for maintainers\n\n## Next Section\n"
+
+	toc := NewGenerator("", 0, []string{"Internal notes"}).GenerateFromContent(content)
+	if !strings.Contains(toc, "[Next Section](#next-section)") || strings.Contains(toc, "[Short]") {
+		t.Errorf("the label of an excluded heading must not move to the next heading, got:\n%s", toc)
+	}
+}
+
+func TestGenerateNumberedFileHonorsLabels(t *testing.T) {
+	content := "# Intro\n\n<!-- gtoc:label \"Setup\" -->\n# How to set up the project\n\n# Frequently asked questions\n"
+	path := writeNumberingFile(t, content)
+
+	got, err := NewGenerator(path, 0, nil, WithMaxLabelLength(12)).GenerateNumberedFile()
+	if err != nil {
+		t.Fatalf("GenerateNumberedFile failed: %v", err)
+	}
+
+	for _, want := range []string{
+		"[2. Setup](#2-how-to-set-up-the-project)<br>",
+		"[3. Frequently…](#3-frequently-asked-questions)<br>",
+		"# 2. How to set up the project\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("result should contain %q, got:\n%s", want, got)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package generator
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// anchorLinkPattern matches in-document links to an anchor, either markdown
+// ("[text](#anchor)") or HTML ("href=\"#anchor\""). The anchor is captured
+// in the first or second group respectively.
+var anchorLinkPattern = regexp.MustCompile(`\]\(#([^)\s]*)\)|href="#([^"]*)"`)
+
+// htmlAnchorPattern matches explicit HTML anchors such as
+// <a name="readme-top"></a> or <div id="intro">.
+var htmlAnchorPattern = regexp.MustCompile(`\b(?:name|id)="([^"]+)"`)
+
+// AnchorLink is an in-document link to an anchor ("#section").
+type AnchorLink struct {
+	Anchor string
+	Line   int
+	Start  int
+	End    int
+}
+
+// FindAnchorLinks returns every in-document anchor link in content, skipping
+// fenced code blocks. Line is 1-based; Start and End are the byte offsets of
+// the anchor (without the leading "#") within the line. Percent-encoded
+// anchors are decoded so they compare equal to Heading.Anchor.
+func FindAnchorLinks(content string) []AnchorLink {
+	var links []AnchorLink
+	filter := &lineFilter{}
+
+	for i, line := range strings.Split(content, "\n") {
+		if marker := fenceMarker(line); marker != "" {
+			filter.toggleFence(marker)
+			continue
+		}
+		if filter.inCodeFence {
+			continue
+		}
+
+		for _, m := range anchorLinkPattern.FindAllStringSubmatchIndex(line, -1) {
+			start, end := m[2], m[3]
+			if start < 0 {
+				start, end = m[4], m[5]
+			}
+			links = append(links, AnchorLink{
+				Anchor: decodeAnchor(line[start:end]),
+				Line:   i + 1,
+				Start:  start,
+				End:    end,
+			})
+		}
+	}
+
+	return links
+}
+
+// AnchorTargets returns every anchor a link in content can point to, mapped
+// to the 1-based line that defines it: the anchor of each heading (without
+// depth or exclusion filtering, since links may target any heading) and any
+// explicit HTML name or id attribute. Heading anchors use GitHub's slug
+// style; see Generator.AnchorTargets for other styles.
+func AnchorTargets(content string) map[string]int {
+	return NewGenerator("", 0, nil).AnchorTargets(content)
+}
+
+// AnchorTargets is like the AnchorTargets function but slugs headings with
+// the generator's Slugger.
+func (g *Generator) AnchorTargets(content string) map[string]int {
+	targets := map[string]int{}
+
+	all := NewGenerator("", 0, nil, WithSlugger(g.slug))
+	for _, h := range all.ParseHeadings(content) {
+		targets[h.Anchor] = h.Line
+	}
+
+	filter := &lineFilter{}
+	for i, line := range strings.Split(content, "\n") {
+		if filter.skip(line) {
+			continue
+		}
+		for _, m := range htmlAnchorPattern.FindAllStringSubmatch(line, -1) {
+			if _, ok := targets[m[1]]; !ok {
+				targets[m[1]] = i + 1
+			}
+		}
+	}
+
+	return targets
+}
+
+// decodeAnchor percent-decodes an anchor, returning it unchanged when it is
+// not valid percent-encoding.
+func decodeAnchor(anchor string) string {
+	decoded, err := url.PathUnescape(anchor)
+	if err != nil {
+		return anchor
+	}
+	return decoded
+}
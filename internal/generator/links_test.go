@@ -0,0 +1,38 @@
+package generator
+
+import "testing"
+
+func TestFindAnchorLinks(t *testing.T) {
+	content := "See [a](#first) and <a href=\"#readme-top\">top</a>.\n" +
+		"```\n[skip](#in-code)\n```\n" +
+		"[Acentos](#instala%C3%A7%C3%A3o)\n"
+
+	links := FindAnchorLinks(content)
+	want := []string{"first", "readme-top", "instalação"}
+	if len(links) != len(want) {
+		t.Fatalf("FindAnchorLinks() = %+v, want anchors %v", links, want)
+	}
+	for i, anchor := range want {
+		if links[i].Anchor != anchor {
+			t.Errorf("link %d anchor = %q, want %q", i, links[i].Anchor, anchor)
+		}
+	}
+
+	if first := links[0]; first.Line != 1 || content[first.Start:first.End] != "first" {
+		t.Errorf("link offsets should cover the anchor text, got %+v", first)
+	}
+}
+
+func TestAnchorTargets(t *testing.T) {
+	content := "<a name=\"readme-top\"></a>\n\n# Setup\n\n# Setup\n\n```\n# Not a heading\n```\n"
+
+	targets := AnchorTargets(content)
+	for anchor, line := range map[string]int{"readme-top": 1, "setup": 3, "setup-1": 5} {
+		if got, ok := targets[anchor]; !ok || got != line {
+			t.Errorf("targets[%q] = %d, %v; want line %d", anchor, got, ok, line)
+		}
+	}
+	if _, ok := targets["not-a-heading"]; ok {
+		t.Error("headings inside code fences must not become anchor targets")
+	}
+}
@@ -0,0 +1,253 @@
+package generator
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// NumberFormat selects how a single level of an outline number is written.
+type NumberFormat int
+
+const (
+	// FormatArabic writes 1, 2, 3, ...
+	FormatArabic NumberFormat = iota
+	// FormatUpperRoman writes I, II, III, ...
+	FormatUpperRoman
+	// FormatLowerRoman writes i, ii, iii, ...
+	FormatLowerRoman
+	// FormatUpperAlpha writes A, B, ..., Z, AA, AB, ...
+	FormatUpperAlpha
+	// FormatLowerAlpha writes a, b, ..., z, aa, ab, ...
+	FormatLowerAlpha
+)
+
+// numberFormatTokens maps the tokens accepted by ParseNumberFormats to
+// their format.
+var numberFormatTokens = map[string]NumberFormat{
+	"1": FormatArabic,
+	"I": FormatUpperRoman,
+	"i": FormatLowerRoman,
+	"A": FormatUpperAlpha,
+	"a": FormatLowerAlpha,
+}
+
+// numberFormatPatterns matches a single number written in each format.
+var numberFormatPatterns = map[NumberFormat]string{
+	FormatArabic:     `\d+`,
+	FormatUpperRoman: `[IVXLCDM]+`,
+	FormatLowerRoman: `[ivxlcdm]+`,
+	FormatUpperAlpha: `[A-Z]+`,
+	FormatLowerAlpha: `[a-z]+`,
+}
+
+// romanNumerals lists the values and symbols used to write roman numerals,
+// largest first.
+var romanNumerals = []struct {
+	value  int
+	symbol string
+}{
+	{1000, "M"}, {900, "CM"}, {500, "D"}, {400, "CD"},
+	{100, "C"}, {90, "XC"}, {50, "L"}, {40, "XL"},
+	{10, "X"}, {9, "IX"}, {5, "V"}, {4, "IV"}, {1, "I"},
+}
+
+// NumberingScheme describes how outline numbers are written and which
+// heading levels receive one. The zero value numbers every heading with
+// dotted Arabic numbers ("1.", "1.2.", ...) starting at the shallowest
+// heading level present.
+type NumberingScheme struct {
+	// Formats gives the format of each numbered level, outermost first.
+	// Levels beyond the list reuse its last format.
+	Formats []NumberFormat
+	// Separator joins the per-level numbers; it defaults to ".".
+	Separator string
+	// Prefix is written before every number, e.g. "§".
+	Prefix string
+	// FromLevel is the heading level numbering starts at; shallower
+	// headings stay unnumbered. Zero means the shallowest level present.
+	FromLevel int
+	// Depth is the deepest heading level that is numbered. Zero means
+	// unlimited. It is independent of the TOC depth.
+	Depth int
+}
+
+// ParseNumberFormats parses a comma-separated list of per-level number
+// formats such as "I,1,a": "1" for Arabic, "I"/"i" for upper/lower roman,
+// and "A"/"a" for upper/lower letters.
+func ParseNumberFormats(spec string) ([]NumberFormat, error) {
+	if strings.TrimSpace(spec) == "" {
+		return nil, nil
+	}
+
+	var formats []NumberFormat
+	for _, token := range strings.Split(spec, ",") {
+		format, ok := numberFormatTokens[strings.TrimSpace(token)]
+		if !ok {
+			return nil, fmt.Errorf("invalid number format %q (use 1, I, i, A or a)", strings.TrimSpace(token))
+		}
+		formats = append(formats, format)
+	}
+	return formats, nil
+}
+
+// separator returns the configured separator or the default ".".
+func (s NumberingScheme) separator() string {
+	if s.Separator == "" {
+		return "."
+	}
+	return s.Separator
+}
+
+// formatAt returns the format of the numbered level at index i.
+func (s NumberingScheme) formatAt(i int) NumberFormat {
+	if len(s.Formats) == 0 {
+		return FormatArabic
+	}
+	if i >= len(s.Formats) {
+		return s.Formats[len(s.Formats)-1]
+	}
+	return s.Formats[i]
+}
+
+// label writes counters as an outline number such as "1.2." or "§II.b.".
+// In an appendix the top-level number is written as a letter ("A.1.").
+func (s NumberingScheme) label(counters []int, appendix bool) string {
+	parts := make([]string, len(counters))
+	for i, c := range counters {
+		format := s.formatAt(i)
+		if appendix && i == 0 {
+			format = FormatUpperAlpha
+		}
+		parts[i] = formatNumber(c, format)
+	}
+	return s.Prefix + strings.Join(parts, s.separator()) + "."
+}
+
+// prefixPattern matches an outline number written by this scheme at the
+// start of a heading, followed by whitespace, so re-numbering and stripping
+// stay idempotent. In an appendix the top-level number may also be a letter.
+// The default scheme outside an appendix matches the same text as
+// existingNumberPrefix.
+func (s NumberingScheme) prefixPattern(appendix bool) *regexp.Regexp {
+	if !appendix && s.Prefix == "" && s.Separator == "" && len(s.Formats) == 0 {
+		return existingNumberPrefix
+	}
+
+	token := formatAlternatives(append([]NumberFormat{FormatArabic}, s.Formats...))
+	first := token
+	if appendix {
+		first = formatAlternatives(append([]NumberFormat{FormatArabic, FormatUpperAlpha}, s.Formats...))
+	}
+	return regexp.MustCompile("^" + regexp.QuoteMeta(s.Prefix) + first +
+		"(?:" + regexp.QuoteMeta(s.separator()) + token + ")*\\.\\s+")
+}
+
+// formatAlternatives returns a regular expression group matching a number
+// written in any of formats.
+func formatAlternatives(formats []NumberFormat) string {
+	seen := map[NumberFormat]bool{}
+	var alternatives []string
+	for _, f := range formats {
+		if !seen[f] {
+			seen[f] = true
+			alternatives = append(alternatives, numberFormatPatterns[f])
+		}
+	}
+	return "(?:" + strings.Join(alternatives, "|") + ")"
+}
+
+// formatNumber writes n in the given format.
+func formatNumber(n int, format NumberFormat) string {
+	switch format {
+	case FormatUpperRoman:
+		return roman(n)
+	case FormatLowerRoman:
+		return strings.ToLower(roman(n))
+	case FormatUpperAlpha:
+		return alpha(n)
+	case FormatLowerAlpha:
+		return strings.ToLower(alpha(n))
+	default:
+		return strconv.Itoa(n)
+	}
+}
+
+// roman writes n as an upper-case roman numeral. Numbers below 1 have no
+// roman form and fall back to Arabic digits.
+func roman(n int) string {
+	if n < 1 {
+		return strconv.Itoa(n)
+	}
+
+	var sb strings.Builder
+	for _, r := range romanNumerals {
+		for n >= r.value {
+			sb.WriteString(r.symbol)
+			n -= r.value
+		}
+	}
+	return sb.String()
+}
+
+// alpha writes n in bijective base 26: A..Z, then AA, AB, ... Numbers below
+// 1 fall back to Arabic digits.
+func alpha(n int) string {
+	if n < 1 {
+		return strconv.Itoa(n)
+	}
+
+	var letters []byte
+	for n > 0 {
+		n--
+		letters = append([]byte{byte('A' + n%26)}, letters...)
+		n /= 26
+	}
+	return string(letters)
+}
+
+// numberer hands out outline numbers for a sequence of headings according
+// to a scheme.
+type numberer struct {
+	scheme   NumberingScheme
+	base     int
+	counters []int
+	appendix bool
+}
+
+// newNumberer returns a numberer whose first numbered level is the scheme's
+// FromLevel or, when unset, minLevel.
+func newNumberer(scheme NumberingScheme, minLevel int) *numberer {
+	base := minLevel
+	if scheme.FromLevel > 0 {
+		base = scheme.FromLevel
+	}
+	return &numberer{scheme: scheme, base: base}
+}
+
+// next advances the counters for a heading at level and returns its outline
+// number, or "" when the level is outside the numbered range.
+func (n *numberer) next(level int) string {
+	if level < n.base || (n.scheme.Depth > 0 && level > n.scheme.Depth) {
+		return ""
+	}
+
+	rel := level - n.base
+	if rel < len(n.counters) {
+		n.counters = n.counters[:rel+1]
+	} else {
+		for len(n.counters) <= rel {
+			n.counters = append(n.counters, 0)
+		}
+	}
+	n.counters[rel]++
+	return n.scheme.label(n.counters, n.appendix)
+}
+
+// startAppendix restarts the counters and switches the top-level number to
+// letters for every following heading.
+func (n *numberer) startAppendix() {
+	n.appendix = true
+	n.counters = nil
+}
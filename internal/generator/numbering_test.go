@@ -75,3 +75,212 @@ func TestGenerateNumberedFileIsIdempotent(t *testing.T) {
 		t.Error("re-numbering must strip the existing number instead of stacking a new one")
 	}
 }
+
+func TestGenerateUnnumberedFile(t *testing.T) {
+	content := "<!-- START_TABLE_OF_CONTENTS -->\n<!-- END_TABLE_OF_CONTENTS -->\n\n" +
+		"# 1. First\n\nSee [Sub A](#11-sub-a) and <a href=\"#2-second\">Second</a>.\n\n" +
+		"## 1.1. Sub A\n\n# 2. Second\n\n```\n# 3. Not a heading\n```\n"
+	path := writeNumberingFile(t, content)
+
+	got, err := NewGenerator(path, 0, nil).GenerateUnnumberedFile()
+	if err != nil {
+		t.Fatalf("GenerateUnnumberedFile failed: %v", err)
+	}
+
+	for _, want := range []string{
+		"# First\n",
+		"## Sub A\n",
+		"# Second\n",
+		"See [Sub A](#sub-a) and <a href=\"#second\">Second</a>.",
+		"# 3. Not a heading\n",
+		"1\\. [First](#first)<br>",
+		"&nbsp;&nbsp;&nbsp;1\\.1. [Sub A](#sub-a)<br>",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("result should contain %q, got:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "# 1. First") || strings.Contains(got, "#11-sub-a") {
+		t.Errorf("numbers and numbered anchors should be gone, got:\n%s", got)
+	}
+}
+
+func TestNumberThenUnnumberRoundTrips(t *testing.T) {
+	original := "# First\n\nSee [Sub A](#sub-a).\n\n## Sub A\n\n# Second\n"
+	path := writeNumberingFile(t, original)
+	gen := NewGenerator(path, 0, nil)
+
+	numbered, err := gen.GenerateNumberedFile()
+	if err != nil {
+		t.Fatalf("GenerateNumberedFile failed: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(numbered), 0644); err != nil {
+		t.Fatalf("failed to write numbered file: %v", err)
+	}
+
+	unnumbered, err := gen.GenerateUnnumberedFile()
+	if err != nil {
+		t.Fatalf("GenerateUnnumberedFile failed: %v", err)
+	}
+
+	toc, err := NewGenerator(writeNumberingFile(t, original), 0, nil).Generate()
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	if want := NewGenerator("", 0, nil).GetFileWithUpdatedTOC(original, toc); unnumbered != want {
+		t.Errorf("unnumbering should restore the plain document:\ngot:\n%s\nwant:\n%s", unnumbered, want)
+	}
+}
+
+func TestNumberingSchemeLabel(t *testing.T) {
+	tests := []struct {
+		name     string
+		scheme   NumberingScheme
+		counters []int
+		want     string
+	}{
+		{"default", NumberingScheme{}, []int{1, 2, 3}, "1.2.3."},
+		{"roman chapters", NumberingScheme{Formats: []NumberFormat{FormatUpperRoman, FormatArabic}}, []int{14, 2}, "XIV.2."},
+		{"last format repeats", NumberingScheme{Formats: []NumberFormat{FormatArabic, FormatLowerAlpha}}, []int{1, 2, 28}, "1.b.ab."},
+		{"lower roman", NumberingScheme{Formats: []NumberFormat{FormatLowerRoman}}, []int{4}, "iv."},
+		{"letters", NumberingScheme{Formats: []NumberFormat{FormatUpperAlpha}}, []int{27}, "AA."},
+		{"prefix", NumberingScheme{Prefix: "§"}, []int{3, 1}, "§3.1."},
+		{"separator", NumberingScheme{Separator: "-"}, []int{3, 1}, "3-1."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.scheme.label(tt.counters, false); got != tt.want {
+				t.Errorf("label(%v) = %q, want %q", tt.counters, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseNumberFormats(t *testing.T) {
+	got, err := ParseNumberFormats("I, 1,a")
+	if err != nil {
+		t.Fatalf("ParseNumberFormats failed: %v", err)
+	}
+	want := []NumberFormat{FormatUpperRoman, FormatArabic, FormatLowerAlpha}
+	if len(got) != len(want) {
+		t.Fatalf("ParseNumberFormats = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("format %d = %v, want %v", i, got[i], want[i])
+		}
+	}
+
+	if _, err := ParseNumberFormats("1,x"); err == nil {
+		t.Error("expected an error for an unknown format token")
+	}
+}
+
+func TestGenerateNumberedFileWithScheme(t *testing.T) {
+	content := "# Title\n\n## Intro\n\n### Scope\n\n#### Detail\n\n## Usage\n"
+	path := writeNumberingFile(t, content)
+
+	scheme := NumberingScheme{
+		Formats:   []NumberFormat{FormatUpperRoman, FormatArabic},
+		Prefix:    "§",
+		FromLevel: 2,
+		Depth:     3,
+	}
+	gen := NewGenerator(path, 0, nil, WithNumbering(scheme))
+	got, err := gen.GenerateNumberedFile()
+	if err != nil {
+		t.Fatalf("GenerateNumberedFile failed: %v", err)
+	}
+
+	for _, h := range []string{"# Title\n", "## §I. Intro\n", "### §I.1. Scope\n", "#### Detail\n", "## §II. Usage\n"} {
+		if !strings.Contains(got, h) {
+			t.Errorf("body should contain heading %q, got:\n%s", h, got)
+		}
+	}
+
+	if err := os.WriteFile(path, []byte(got), 0644); err != nil {
+		t.Fatalf("failed to write first result: %v", err)
+	}
+	again, err := gen.GenerateNumberedFile()
+	if err != nil {
+		t.Fatalf("second run failed: %v", err)
+	}
+	if again != got {
+		t.Errorf("numbering with a custom scheme should be idempotent:\nfirst:\n%s\nsecond:\n%s", got, again)
+	}
+
+	stripped, err := gen.GenerateUnnumberedFile()
+	if err != nil {
+		t.Fatalf("GenerateUnnumberedFile failed: %v", err)
+	}
+	for _, h := range []string{"## Intro\n", "### Scope\n", "## Usage\n"} {
+		if !strings.Contains(stripped, h) {
+			t.Errorf("stripping with the same scheme should restore %q, got:\n%s", h, stripped)
+		}
+	}
+}
+
+func TestGenerateUnnumberedFileKeepsLetterProse(t *testing.T) {
+	content := "# a. Intro\n\n## a.a. Scope\n\n## e.g. Examples\n\n# b. Usage\n\n# a. note on usage\n"
+	path := writeNumberingFile(t, content)
+	gen := NewGenerator(path, 0, nil, WithNumbering(NumberingScheme{Formats: []NumberFormat{FormatLowerAlpha}}))
+
+	got, err := gen.GenerateUnnumberedFile()
+	if err != nil {
+		t.Fatalf("GenerateUnnumberedFile failed: %v", err)
+	}
+	for _, want := range []string{"# Intro\n", "## Scope\n", "## e.g. Examples\n", "# Usage\n", "# a. note on usage\n"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("result should contain %q, got:\n%s", want, got)
+		}
+	}
+}
+
+func TestGenerateFromContentHonorsNumberingRange(t *testing.T) {
+	content := "# Title\n\n## Intro\n\n### Scope\n"
+	gen := NewGenerator("", 0, nil, WithNumbering(NumberingScheme{FromLevel: 2, Depth: 2}))
+	toc := gen.GenerateFromContent(content)
+
+	for _, entry := range []string{
+		"[Title](#title)<br>",
+		"&nbsp;&nbsp;&nbsp;1\\. [Intro](#intro)<br>",
+		"&nbsp;&nbsp;&nbsp;&nbsp;&nbsp;&nbsp;[Scope](#scope)<br>",
+	} {
+		if !strings.Contains(toc, entry) {
+			t.Errorf("TOC should contain %q, got:\n%s", entry, toc)
+		}
+	}
+}
+
+func TestGenerateNumberedFileCountsFilteredHeadingsInAnchors(t *testing.T) {
+	content := "# Title\n\n#### Notes\n\n## Notes\n"
+	path := writeNumberingFile(t, content)
+
+	gen := NewGenerator(path, 3, nil, WithNumbering(NumberingScheme{Depth: 1}))
+	got, err := gen.GenerateNumberedFile()
+	if err != nil {
+		t.Fatalf("GenerateNumberedFile failed: %v", err)
+	}
+	if !strings.Contains(got, "[Notes](#notes-1)<br>") {
+		t.Errorf("the unnumbered heading should follow the depth-filtered one, got:\n%s", got)
+	}
+}
+
+func TestGenerateNumberedFileMatchesDuplicateAnchors(t *testing.T) {
+	path := writeNumberingFile(t, "# FAQ\n\n# Usage\n\n# FAQ\n")
+	filter, err := NewHeadingFilter(FilterSubstring, nil, []string{"#faq-1"}, false)
+	if err != nil {
+		t.Fatalf("NewHeadingFilter failed: %v", err)
+	}
+
+	got, err := NewGenerator(path, 0, nil, WithFilter(filter)).GenerateNumberedFile()
+	if err != nil {
+		t.Fatalf("GenerateNumberedFile failed: %v", err)
+	}
+	for _, h := range []string{"# 1. FAQ\n", "# 2. Usage\n", "# FAQ\n"} {
+		if !strings.Contains(got, h) {
+			t.Errorf("#faq-1 should exclude only the second FAQ, want %q in:\n%s", h, got)
+		}
+	}
+}
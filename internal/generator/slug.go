@@ -0,0 +1,127 @@
+package generator
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// Slugger turns heading text into an anchor slug. Duplicate slugs are
+// disambiguated afterwards by uniqueAnchor, so a Slugger only needs to be
+// deterministic.
+type Slugger func(text string) string
+
+// Built-in slug styles, selectable by name with SluggerByName.
+const (
+	// SlugGitHub produces the anchors GitHub renders, keeping unicode
+	// letters ("instalação").
+	SlugGitHub = "github"
+	// SlugASCII transliterates to plain ASCII ("instalacao") for renderers
+	// and tools that mangle non-ASCII anchors.
+	SlugASCII = "ascii"
+)
+
+// sluggers maps slug style names to their implementation.
+var sluggers = map[string]Slugger{
+	SlugGitHub: createAnchor,
+	SlugASCII:  createASCIIAnchor,
+}
+
+// transliterations spells out letters that have no canonical decomposition
+// into an ASCII base letter plus combining marks.
+var transliterations = strings.NewReplacer(
+	"ß", "ss", "æ", "ae", "œ", "oe", "ø", "o", "đ", "d", "ð", "d",
+	"þ", "th", "ł", "l", "ı", "i", "ħ", "h", "ŋ", "ng",
+)
+
+// inlineLinkPattern matches markdown images and inline links, capturing the
+// leading "!" of an image and the link text.
+var inlineLinkPattern = regexp.MustCompile(`(!?)\[([^\]]*)\]\([^)]*\)`)
+
+// htmlTagPattern matches inline HTML opening, closing, and self-closing tags,
+// but not autolinks such as <https://example.com>.
+var htmlTagPattern = regexp.MustCompile(`</?[A-Za-z][A-Za-z0-9-]*(?:\s[^>]*)?/?>`)
+
+// underscoreEmphasisPattern matches text wrapped in underscore emphasis at
+// word boundaries; intraword and backslash-escaped underscores are literal.
+var underscoreEmphasisPattern = regexp.MustCompile(`(^|[^\\\p{L}\p{N}_])_{1,3}([^\s_](?:[^_]*[^\s_\\])?)_{1,3}($|[^\p{L}\p{N}_])`)
+
+// WithSlugger sets how heading text is turned into anchors.
+func WithSlugger(slug Slugger) Option {
+	return func(g *Generator) {
+		g.slug = slug
+	}
+}
+
+// SluggerByName returns the built-in Slugger for a slug style name.
+func SluggerByName(name string) (Slugger, error) {
+	if slug, ok := sluggers[name]; ok {
+		return slug, nil
+	}
+
+	names := make([]string, 0, len(sluggers))
+	for n := range sluggers {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return nil, fmt.Errorf("unknown slug style %q (use %s)", name, strings.Join(names, " or "))
+}
+
+// createASCIIAnchor slugifies text like createAnchor after transliterating
+// it to ASCII: the text is decomposed (NFKD) so accents become combining
+// marks that are dropped, a few letters without a decomposition are spelled
+// out, and any other non-ASCII rune is removed.
+func createASCIIAnchor(text string) string {
+	lowered := strings.ToLower(norm.NFKD.String(plainText(text)))
+
+	var sb strings.Builder
+	for _, r := range transliterations.Replace(lowered) {
+		if r <= unicode.MaxASCII {
+			sb.WriteRune(r)
+		}
+	}
+	return slugify(sb.String())
+}
+
+// plainText reduces the markdown of a heading to the text GitHub renders,
+// which is what its anchors are built from: code spans keep their content
+// verbatim, links keep their text, images, HTML tags, and underscore
+// emphasis are dropped, and HTML entities are decoded.
+func plainText(text string) string {
+	var sb strings.Builder
+	for i, part := range strings.Split(text, "`") {
+		// Odd parts sit between backticks. An unclosed backtick is literal,
+		// and stripped later like any other punctuation.
+		if i%2 == 1 {
+			sb.WriteString(part)
+			continue
+		}
+		sb.WriteString(renderInline(part))
+	}
+	return sb.String()
+}
+
+// renderInline renders markdown outside code spans as plain text.
+func renderInline(s string) string {
+	s = inlineLinkPattern.ReplaceAllStringFunc(s, func(m string) string {
+		parts := inlineLinkPattern.FindStringSubmatch(m)
+		if parts[1] == "!" {
+			return ""
+		}
+		return parts[2]
+	})
+	s = htmlTagPattern.ReplaceAllString(s, "")
+	for {
+		stripped := underscoreEmphasisPattern.ReplaceAllString(s, "$1$2$3")
+		if stripped == s {
+			break
+		}
+		s = stripped
+	}
+	return html.UnescapeString(s)
+}
@@ -0,0 +1,57 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCreateASCIIAnchor(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"Instalação", "instalacao"},
+		{"Ação e Reação", "acao-e-reacao"},
+		{"Straße", "strasse"},
+		{"Ærøskøbing", "aeroskobing"},
+		{"Ｆｕｌｌ ｗｉｄｔｈ", "full-width"},
+		{"ﬁle naming", "file-naming"},
+		{"Café ☕ Bar", "cafe--bar"},
+		{"Łódź", "lodz"},
+		{"日本語 Guide", "guide"},
+		{"Already ascii_text", "already-ascii_text"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			if got := createASCIIAnchor(tt.text); got != tt.want {
+				t.Errorf("createASCIIAnchor(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestASCIISluggerDedupesTransliteratedAnchors(t *testing.T) {
+	slug, err := SluggerByName(SlugASCII)
+	if err != nil {
+		t.Fatalf("SluggerByName failed: %v", err)
+	}
+
+	gen := NewGenerator("", 0, nil, WithSlugger(slug))
+	toc := gen.GenerateFromContent("# Ação\n\n# Acao\n")
+	for _, entry := range []string{"[Ação](#acao)", "[Acao](#acao-1)"} {
+		if !strings.Contains(toc, entry) {
+			t.Errorf("TOC should contain %q, got:\n%s", entry, toc)
+		}
+	}
+
+	if _, ok := gen.AnchorTargets("# Ação\n")["acao"]; !ok {
+		t.Error("AnchorTargets should use the generator's slug style")
+	}
+}
+
+func TestSluggerByNameRejectsUnknownStyles(t *testing.T) {
+	if _, err := SluggerByName("kebab"); err == nil {
+		t.Error("expected an error for an unknown slug style")
+	}
+}
@@ -0,0 +1,165 @@
+package generator
+
+import "strings"
+
+// directiveID pairs a heading with its translations: headings carrying the
+// same "<!-- gtoc:id name -->" in two documents are the same section.
+const directiveID = "id"
+
+// Section is a heading together with the id a gtoc:id directive gave it, if
+// any.
+type Section struct {
+	*Heading
+	ID string
+}
+
+// DifferenceKind classifies how two outlines differ.
+type DifferenceKind string
+
+// The kinds of difference CompareOutlines reports.
+const (
+	// DifferenceMissing is a reference section with no counterpart.
+	DifferenceMissing DifferenceKind = "missing"
+	// DifferenceExtra is a section with no counterpart in the reference.
+	DifferenceExtra DifferenceKind = "extra"
+	// DifferenceReordered is a section whose id exists in both outlines but
+	// at a different position relative to the other sections.
+	DifferenceReordered DifferenceKind = "reordered"
+	// DifferenceLevel is a pair of sections with the same id but different
+	// heading levels.
+	DifferenceLevel DifferenceKind = "level"
+)
+
+// Difference is one structural mismatch between a reference outline and
+// another one. Reference is nil for extra sections and Other is nil for
+// missing ones.
+type Difference struct {
+	Kind      DifferenceKind
+	Reference *Section
+	Other     *Section
+}
+
+// Outline returns the document's headings as sections, attaching each
+// gtoc:id directive to the heading right after it. The directive of a
+// heading left out by the depth limit or the filters is dropped rather than
+// given to the next heading kept.
+func (g *Generator) Outline(content string) []Section {
+	ids := headingDirectives(findDirectives(strings.Split(content, "\n")), directiveID)
+	headings := g.ParseHeadings(content)
+	sections := make([]Section, len(headings))
+	for i, h := range headings {
+		sections[i] = Section{Heading: h, ID: ids[h.Line]}
+	}
+	return sections
+}
+
+// CompareOutlines compares other against ref, the outline it was translated
+// from. Sections are aligned by position and level, and sections with a
+// gtoc:id only ever pair with the section carrying the same id in the other
+// outline. Differences seen from ref come first, in ref's order, followed by
+// those only found in other, in its order.
+func CompareOutlines(ref, other []Section) []Difference {
+	matchedRef, matchedOther := matchSections(ref, other)
+
+	var diffs []Difference
+	for i, j := range matchedRef {
+		switch {
+		case j >= 0 && ref[i].Level != other[j].Level:
+			diffs = append(diffs, Difference{Kind: DifferenceLevel, Reference: &ref[i], Other: &other[j]})
+		case j < 0 && !hasID(other, ref[i].ID):
+			diffs = append(diffs, Difference{Kind: DifferenceMissing, Reference: &ref[i]})
+		}
+	}
+	for j, i := range matchedOther {
+		if i >= 0 {
+			continue
+		}
+		d := Difference{Kind: DifferenceExtra, Other: &other[j]}
+		if k := indexOfID(ref, other[j].ID); k >= 0 {
+			d.Kind, d.Reference = DifferenceReordered, &ref[k]
+		}
+		diffs = append(diffs, d)
+	}
+	return diffs
+}
+
+// matchSections aligns the outlines and returns, for each section of ref,
+// the index of its counterpart in other, and vice versa; -1 marks a section
+// without one.
+func matchSections(ref, other []Section) (matchedRef, matchedOther []int) {
+	matchedRef = make([]int, len(ref))
+	matchedOther = make([]int, len(other))
+	for i := range matchedRef {
+		matchedRef[i] = -1
+	}
+	for j := range matchedOther {
+		matchedOther[j] = -1
+	}
+	for _, pair := range alignOutlines(ref, other) {
+		matchedRef[pair[0]] = pair[1]
+		matchedOther[pair[1]] = pair[0]
+	}
+	return matchedRef, matchedOther
+}
+
+// alignOutlines returns the index pairs of the longest common subsequence of
+// ref and other under sameSection.
+func alignOutlines(ref, other []Section) [][2]int {
+	// lcs[i][j] is the alignment length of ref[i:] and other[j:].
+	lcs := make([][]int, len(ref)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(other)+1)
+	}
+	for i := len(ref) - 1; i >= 0; i-- {
+		for j := len(other) - 1; j >= 0; j-- {
+			if sameSection(ref[i], other[j]) {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var pairs [][2]int
+	for i, j := 0, 0; i < len(ref) && j < len(other); {
+		switch {
+		case sameSection(ref[i], other[j]):
+			pairs = append(pairs, [2]int{i, j})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			i++
+		default:
+			j++
+		}
+	}
+	return pairs
+}
+
+// sameSection reports whether a and b may be paired: by id when either has
+// one, otherwise by heading level.
+func sameSection(a, b Section) bool {
+	if a.ID != "" || b.ID != "" {
+		return a.ID == b.ID
+	}
+	return a.Level == b.Level
+}
+
+// hasID reports whether any section carries the non-empty id.
+func hasID(sections []Section, id string) bool {
+	return indexOfID(sections, id) >= 0
+}
+
+// indexOfID returns the index of the section carrying the non-empty id, or
+// -1.
+func indexOfID(sections []Section, id string) int {
+	if id == "" {
+		return -1
+	}
+	for i, s := range sections {
+		if s.ID == id {
+			return i
+		}
+	}
+	return -1
+}
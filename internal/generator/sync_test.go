@@ -0,0 +1,86 @@
+package generator
+
+import "testing"
+
+func TestCompareOutlines(t *testing.T) {
+	tests := []struct {
+		name  string
+		ref   string
+		other string
+		want  []DifferenceKind
+	}{
+		{
+			name:  "translated headings are in sync",
+			ref:   "# Visão Geral\n\n## Objetivo\n\n# Uso\n",
+			other: "# Overview\n\n## Goal\n\n# Usage\n",
+		},
+		{
+			name:  "missing section",
+			ref:   "# Visão Geral\n\n## Objetivo\n\n## Contexto\n\n# Uso\n",
+			other: "# Overview\n\n## Goal\n\n# Usage\n",
+			want:  []DifferenceKind{DifferenceMissing},
+		},
+		{
+			name:  "extra section",
+			ref:   "# Visão Geral\n\n# Uso\n",
+			other: "# Overview\n\n## Goal\n\n# Usage\n",
+			want:  []DifferenceKind{DifferenceExtra},
+		},
+		{
+			name:  "reordered sections paired by id",
+			ref:   "<!-- gtoc:id a -->\n# A\n\n<!-- gtoc:id b -->\n# B\n\n<!-- gtoc:id c -->\n# C\n",
+			other: "<!-- gtoc:id b -->\n# B\n\n<!-- gtoc:id a -->\n# A\n\n<!-- gtoc:id c -->\n# C\n",
+			want:  []DifferenceKind{DifferenceReordered},
+		},
+		{
+			name:  "level changed on an id-paired section",
+			ref:   "# A\n\n<!-- gtoc:id setup -->\n## Setup\n",
+			other: "# A\n\n<!-- gtoc:id setup -->\n### Setup\n",
+			want:  []DifferenceKind{DifferenceLevel},
+		},
+		{
+			name:  "ids only pair with the same id",
+			ref:   "<!-- gtoc:id install -->\n# Install\n",
+			other: "<!-- gtoc:id usage -->\n# Usage\n",
+			want:  []DifferenceKind{DifferenceMissing, DifferenceExtra},
+		},
+	}
+
+	gen := NewGenerator("", 0, nil)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := CompareOutlines(gen.Outline(tt.ref), gen.Outline(tt.other))
+			if len(got) != len(tt.want) {
+				t.Fatalf("CompareOutlines() returned %d differences, want %d: %+v", len(got), len(tt.want), got)
+			}
+			for i, d := range got {
+				if d.Kind != tt.want[i] {
+					t.Errorf("difference %d kind = %q, want %q", i, d.Kind, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestOutlineAttachesIDs(t *testing.T) {
+	content := "# Intro\n\n<!-- gtoc:id setup -->\n\n## Setup\n\n## Usage\n"
+	sections := NewGenerator("", 0, nil).Outline(content)
+	if len(sections) != 3 {
+		t.Fatalf("expected 3 sections, got %d", len(sections))
+	}
+	if sections[0].ID != "" || sections[1].ID != "setup" || sections[2].ID != "" {
+		t.Errorf("gtoc:id should attach to the next heading only, got %q, %q, %q",
+			sections[0].ID, sections[1].ID, sections[2].ID)
+	}
+}
+
+func TestOutlineDropsIDsOfFilteredHeadings(t *testing.T) {
+	content := "# Intro\n\n<!-- gtoc:id deep -->\n### Deep\n\n## Usage\n"
+	sections := NewGenerator("", 2, nil).Outline(content)
+	if len(sections) != 2 {
+		t.Fatalf("expected 2 sections, got %d", len(sections))
+	}
+	if sections[1].ID != "" {
+		t.Errorf("the id of a heading below --depth must not move to the next heading, got %q", sections[1].ID)
+	}
+}
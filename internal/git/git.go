@@ -0,0 +1,146 @@
+package git
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// ErrNotWorkTree is returned when a command that needs a git repository is
+// run outside of a git work tree.
+var ErrNotWorkTree = errors.New("not inside a git work tree")
+
+// Run executes git with args in dir and returns its trimmed standard output.
+// When git exits with a non-zero status, the returned error carries git's
+// standard error so the caller can surface it verbatim.
+func Run(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			msg = err.Error()
+		}
+		return "", fmt.Errorf("git %s: %s", strings.Join(args, " "), msg)
+	}
+
+	return strings.TrimSpace(stdout.String()), nil
+}
+
+// TopLevel returns the absolute path of the root of the work tree containing
+// dir, or ErrNotWorkTree when dir is not inside one.
+func TopLevel(dir string) (string, error) {
+	if _, err := exec.LookPath("git"); err != nil {
+		return "", fmt.Errorf("git executable not found in PATH: %w", err)
+	}
+
+	out, err := Run(dir, "rev-parse", "--is-inside-work-tree")
+	if err != nil || out != "true" {
+		return "", ErrNotWorkTree
+	}
+
+	return Run(dir, "rev-parse", "--show-toplevel")
+}
+
+// HooksDir returns the absolute path of the directory git reads hooks from
+// for the repository containing dir. It honors core.hooksPath and linked
+// worktrees because the path is resolved by git itself.
+func HooksDir(dir string) (string, error) {
+	root, err := TopLevel(dir)
+	if err != nil {
+		return "", err
+	}
+
+	hooks, err := Run(root, "rev-parse", "--git-path", "hooks")
+	if err != nil {
+		return "", err
+	}
+
+	if !filepath.IsAbs(hooks) {
+		hooks = filepath.Join(root, hooks)
+	}
+	return hooks, nil
+}
+
+// StagedFiles returns the absolute paths of files staged in the index of the
+// repository containing dir, restricted to pathspecs. Deleted files are
+// omitted since there is nothing left to process.
+func StagedFiles(dir string, pathspecs []string) ([]string, error) {
+	root, err := TopLevel(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	args := append([]string{"diff", "--cached", "--name-only", "-z", "--diff-filter=ACMR", "--"}, pathspecs...)
+	return listFiles(dir, root, args)
+}
+
+// UnstagedFiles returns the absolute paths of the files among pathspecs
+// whose working tree content differs from the index of the repository
+// containing dir.
+func UnstagedFiles(dir string, pathspecs []string) ([]string, error) {
+	root, err := TopLevel(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	args := append([]string{"diff", "--name-only", "-z", "--"}, pathspecs...)
+	return listFiles(dir, root, args)
+}
+
+// Add stages paths in the index of the repository containing dir.
+func Add(dir string, paths []string) error {
+	_, err := Run(dir, append([]string{"add", "--"}, paths...)...)
+	return err
+}
+
+// ChangedFiles returns the absolute paths of files that differ between ref
+// and the working tree of the repository containing dir, restricted to
+// pathspecs. When the merge base of ref and HEAD is known, changes are taken
+// relative to it so commits that only landed on ref are ignored; shallow
+// clones often lack that history, in which case ref itself is the baseline.
+func ChangedFiles(dir, ref string, pathspecs []string) ([]string, error) {
+	root, err := TopLevel(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := Run(dir, "rev-parse", "--verify", "--quiet", ref+"^{commit}"); err != nil {
+		return nil, fmt.Errorf("unknown git ref %q (shallow clones may need it fetched first, e.g. git fetch --depth=1 origin main)", ref)
+	}
+
+	base := ref
+	if mergeBase, err := Run(dir, "merge-base", ref, "HEAD"); err == nil {
+		base = mergeBase
+	}
+
+	args := append([]string{"diff", "--name-only", "-z", "--diff-filter=ACMR", base, "--"}, pathspecs...)
+	return listFiles(dir, root, args)
+}
+
+// listFiles runs a git command in dir, so pathspecs are resolved relative to
+// it, and returns the NUL-separated, root-relative paths it prints as
+// absolute paths.
+func listFiles(dir, root string, args []string) ([]string, error) {
+	out, err := Run(dir, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []string
+	for _, name := range strings.Split(out, "\x00") {
+		if name == "" {
+			continue
+		}
+		files = append(files, filepath.Join(root, filepath.FromSlash(name)))
+	}
+	return files, nil
+}
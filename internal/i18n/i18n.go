@@ -0,0 +1,244 @@
+package i18n
+
+import (
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// DefaultLanguage is used when a document's language is neither given nor
+// detected.
+const DefaultLanguage = "en"
+
+// Messages are the user-visible strings gtoc writes into documents.
+type Messages struct {
+	// LanguageName is the language's name in itself, used in the language
+	// bar linking translations.
+	LanguageName    string `json:"languageName,omitempty"`
+	BackToTop       string `json:"backToTop,omitempty"`
+	TableOfContents string `json:"tableOfContents,omitempty"`
+}
+
+// builtin is the message catalog shipped with gtoc, keyed by BCP 47 tag.
+var builtin = map[string]Messages{
+	"en":    {LanguageName: "English", BackToTop: "back to top", TableOfContents: "Table of Contents"},
+	"pt-BR": {LanguageName: "Português", BackToTop: "voltar ao topo", TableOfContents: "Sumário"},
+	"es":    {LanguageName: "Español", BackToTop: "volver arriba", TableOfContents: "Tabla de contenidos"},
+	"fr":    {LanguageName: "Français", BackToTop: "retour en haut", TableOfContents: "Table des matières"},
+	"de":    {LanguageName: "Deutsch", BackToTop: "nach oben", TableOfContents: "Inhaltsverzeichnis"},
+	"it":    {LanguageName: "Italiano", BackToTop: "torna su", TableOfContents: "Indice"},
+}
+
+// frontMatterLangPattern matches a language declaration in YAML front
+// matter, such as "lang: pt-BR" or `language: "es"`.
+var frontMatterLangPattern = regexp.MustCompile(`^(?:lang|language|locale):\s*["']?([A-Za-z]{2,3}(?:[-_][A-Za-z0-9]+)*)["']?\s*$`)
+
+// fileNameTagPattern matches the shape of a language tag used as a file
+// name suffix: a primary language subtag optionally followed by a region or
+// script subtag, so "notes" or "de-notes" are never taken for languages.
+var fileNameTagPattern = regexp.MustCompile(`^[A-Za-z]{2,3}(?:[-_.](?:[A-Za-z]{2}|[0-9]{3}|[A-Za-z]{4}))?$`)
+
+// backToTopPattern matches a back-to-top link in any language.
+var backToTopPattern = regexp.MustCompile(`<p align="right">\(<a href="#readme-top">[^<]*</a>\)</p>`)
+
+// Default returns the messages of DefaultLanguage.
+func Default() Messages {
+	return builtin[DefaultLanguage]
+}
+
+// BackToTopLink returns the back-to-top link written at the end of sections
+// and after the TOC, labeled with text.
+func BackToTopLink(text string) string {
+	return `<p align="right">(<a href="#readme-top">` + text + `</a>)</p>`
+}
+
+// FindBackToTopLink returns the position of the first back-to-top link in s,
+// whatever its language, or nil when there is none.
+func FindBackToTopLink(s string) []int {
+	return backToTopPattern.FindStringIndex(s)
+}
+
+// Catalog holds the messages of every known language: the built-in ones
+// with user overrides applied on top.
+type Catalog struct {
+	languages map[string]Messages
+}
+
+// NewCatalog returns the built-in catalog with overrides applied. An
+// override replaces only the strings it sets and may add new languages,
+// whose missing strings fall back to DefaultLanguage.
+func NewCatalog(overrides map[string]Messages) *Catalog {
+	c := &Catalog{languages: map[string]Messages{}}
+	for tag, m := range builtin {
+		c.languages[tag] = m
+	}
+	for tag, m := range overrides {
+		// A new language borrows the default strings, but not its name.
+		base := Default()
+		base.LanguageName = ""
+		if existing, ok := c.exact(tag); ok {
+			base, tag = existing.Messages, existing.tag
+		}
+		c.languages[tag] = merge(base, m)
+	}
+	return c
+}
+
+// tagged is a catalog entry together with its canonical tag.
+type tagged struct {
+	Messages
+	tag string
+}
+
+// Lookup returns the messages for lang. Tags match case-insensitively and
+// "_" is accepted for "-"; a bare or unknown regional tag falls back to a
+// language sharing its primary subtag, so "pt" and "pt-PT" find "pt-BR".
+func (c *Catalog) Lookup(lang string) (Messages, bool) {
+	m, ok := c.lookup(lang)
+	return m.Messages, ok
+}
+
+// Messages returns the messages for lang, or those of DefaultLanguage when
+// lang is unknown.
+func (c *Catalog) Messages(lang string) Messages {
+	if m, ok := c.Lookup(lang); ok {
+		return m
+	}
+	return c.languages[DefaultLanguage]
+}
+
+// Languages returns the known language tags, sorted.
+func (c *Catalog) Languages() []string {
+	tags := make([]string, 0, len(c.languages))
+	for tag := range c.languages {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+	return tags
+}
+
+// lookup resolves lang to a catalog entry.
+func (c *Catalog) lookup(lang string) (tagged, bool) {
+	lang = strings.ReplaceAll(strings.TrimSpace(lang), "_", "-")
+	if lang == "" {
+		return tagged{}, false
+	}
+
+	if m, ok := c.exact(lang); ok {
+		return m, true
+	}
+
+	primary, _, _ := strings.Cut(lang, "-")
+	for _, tag := range c.Languages() {
+		if p, _, _ := strings.Cut(tag, "-"); strings.EqualFold(p, primary) {
+			return tagged{c.languages[tag], tag}, true
+		}
+	}
+	return tagged{}, false
+}
+
+// exact resolves lang to the catalog entry with the same tag, ignoring case
+// and accepting "_" for "-".
+func (c *Catalog) exact(lang string) (tagged, bool) {
+	lang = strings.ReplaceAll(strings.TrimSpace(lang), "_", "-")
+	for tag, m := range c.languages {
+		if strings.EqualFold(tag, lang) {
+			return tagged{m, tag}, true
+		}
+	}
+	return tagged{}, false
+}
+
+// Resolve returns the language of a document: forced when set, otherwise
+// the language declared in its front matter, otherwise a known language
+// suffix of its file name (README_en.md, README.pt-BR.md), otherwise
+// fallback.
+func (c *Catalog) Resolve(forced, fallback, path, content string) string {
+	if forced != "" {
+		return forced
+	}
+	if lang := FrontMatterLanguage(content); lang != "" {
+		return lang
+	}
+	if lang := c.FileNameLanguage(path); lang != "" {
+		return lang
+	}
+	return fallback
+}
+
+// FrontMatterLanguage returns the language declared by a lang, language or
+// locale key in content's YAML front matter, or "".
+func FrontMatterLanguage(content string) string {
+	rest, ok := strings.CutPrefix(content, "---\n")
+	if !ok {
+		return ""
+	}
+
+	for _, line := range strings.Split(rest, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "---" || line == "..." {
+			break
+		}
+		if m := frontMatterLangPattern.FindStringSubmatch(line); m != nil {
+			return strings.ReplaceAll(m[1], "_", "-")
+		}
+	}
+	return ""
+}
+
+// FileNameLanguage returns the language named by the suffix of path's file
+// name, such as "en" for README_en.md or "pt-BR" for README.pt_BR.md, or ""
+// when the suffix is not a known language.
+func (c *Catalog) FileNameLanguage(path string) string {
+	_, lang := c.SplitFileName(path)
+	return lang
+}
+
+// SplitFileName splits path's file name, without its extension, into the
+// stem shared by every translation of the document and the language named
+// by its suffix: "README_en.md" gives "README" and "en". A name without a
+// known language suffix is returned whole with an empty language.
+func (c *Catalog) SplitFileName(path string) (stem, lang string) {
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	isSep := func(r rune) bool { return r == '_' || r == '.' }
+
+	last := strings.LastIndexFunc(name, isSep)
+	if last <= 0 {
+		return name, ""
+	}
+	if prev := strings.LastIndexFunc(name[:last], isSep); prev > 0 {
+		if tag := c.suffixLanguage(name[prev+1:]); tag != "" {
+			return name[:prev], tag
+		}
+	}
+	if tag := c.suffixLanguage(name[last+1:]); tag != "" {
+		return name[:last], tag
+	}
+	return name, ""
+}
+
+// suffixLanguage returns the catalog tag a file name suffix names, or "".
+func (c *Catalog) suffixLanguage(suffix string) string {
+	if !fileNameTagPattern.MatchString(suffix) {
+		return ""
+	}
+	if m, ok := c.lookup(strings.ReplaceAll(suffix, ".", "-")); ok {
+		return m.tag
+	}
+	return ""
+}
+
+// merge returns base with every string set in override replaced.
+func merge(base, override Messages) Messages {
+	if override.LanguageName != "" {
+		base.LanguageName = override.LanguageName
+	}
+	if override.BackToTop != "" {
+		base.BackToTop = override.BackToTop
+	}
+	if override.TableOfContents != "" {
+		base.TableOfContents = override.TableOfContents
+	}
+	return base
+}
@@ -0,0 +1,118 @@
+package i18n
+
+import "testing"
+
+func TestCatalogLookup(t *testing.T) {
+	c := NewCatalog(nil)
+
+	tests := []struct {
+		lang string
+		want string
+		ok   bool
+	}{
+		{"en", "back to top", true},
+		{"pt-BR", "voltar ao topo", true},
+		{"pt_br", "voltar ao topo", true},
+		{"pt", "voltar ao topo", true},
+		{"es-MX", "volver arriba", true},
+		{"xx", "", false},
+		{"", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.lang, func(t *testing.T) {
+			got, ok := c.Lookup(tt.lang)
+			if ok != tt.ok || got.BackToTop != tt.want {
+				t.Errorf("Lookup(%q) = %q, %v; want %q, %v", tt.lang, got.BackToTop, ok, tt.want, tt.ok)
+			}
+		})
+	}
+
+	if got := c.Messages("xx").BackToTop; got != "back to top" {
+		t.Errorf("unknown languages should fall back to English, got %q", got)
+	}
+}
+
+func TestCatalogOverrides(t *testing.T) {
+	c := NewCatalog(map[string]Messages{
+		"pt-br": {BackToTop: "voltar"},
+		"nl":    {BackToTop: "terug naar boven"},
+	})
+
+	pt := c.Messages("pt-BR")
+	if pt.BackToTop != "voltar" || pt.TableOfContents != "Sumário" {
+		t.Errorf("override should replace only the strings it sets, got %+v", pt)
+	}
+	nl := c.Messages("nl")
+	if nl.BackToTop != "terug naar boven" || nl.TableOfContents != "Table of Contents" {
+		t.Errorf("a new language should fall back to English for missing strings, got %+v", nl)
+	}
+}
+
+func TestCatalogResolve(t *testing.T) {
+	c := NewCatalog(nil)
+
+	tests := []struct {
+		name     string
+		forced   string
+		fallback string
+		path     string
+		content  string
+		want     string
+	}{
+		{"forced wins", "es", "", "README_en.md", "---\nlang: fr\n---\n", "es"},
+		{"front matter", "", "", "README_en.md", "---\ntitle: x\nlang: \"fr\"\n---\n# T\n", "fr"},
+		{"underscore suffix", "", "", "docs/README_en.md", "# T\n", "en"},
+		{"dotted region suffix", "", "", "README.pt-BR.md", "# T\n", "pt-BR"},
+		{"underscored region suffix", "", "", "README_pt_BR.md", "# T\n", "pt-BR"},
+		{"unknown suffix", "", "pt-BR", "getting_started.md", "# T\n", "pt-BR"},
+		{"bare name is not a language", "", "", "es.md", "# T\n", ""},
+		{"front matter must come first", "", "", "doc.md", "# T\n---\nlang: fr\n---\n", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := c.Resolve(tt.forced, tt.fallback, tt.path, tt.content); got != tt.want {
+				t.Errorf("Resolve() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFindBackToTopLink(t *testing.T) {
+	s := "text\n" + BackToTopLink("voltar ao topo") + "\n"
+	loc := FindBackToTopLink(s)
+	if loc == nil || s[loc[0]:loc[1]] != BackToTopLink("voltar ao topo") {
+		t.Errorf("FindBackToTopLink should find a link in any language, got %v", loc)
+	}
+	if FindBackToTopLink("no link here") != nil {
+		t.Error("FindBackToTopLink should return nil without a link")
+	}
+}
+
+func TestSplitFileName(t *testing.T) {
+	c := NewCatalog(nil)
+
+	tests := []struct {
+		path string
+		stem string
+		lang string
+	}{
+		{"README.md", "README", ""},
+		{"docs/README_en.md", "README", "en"},
+		{"README.pt-BR.md", "README", "pt-BR"},
+		{"README_pt_BR.md", "README", "pt-BR"},
+		{"getting_started.md", "getting_started", ""},
+		{"release_de_notes.md", "release_de_notes", ""},
+		{"es.md", "es", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			stem, lang := c.SplitFileName(tt.path)
+			if stem != tt.stem || lang != tt.lang {
+				t.Errorf("SplitFileName(%q) = %q, %q; want %q, %q", tt.path, stem, lang, tt.stem, tt.lang)
+			}
+		})
+	}
+}
@@ -0,0 +1,145 @@
+package lint
+
+import (
+	"fmt"
+	"strings"
+)
+
+// IDs of the code fence rules.
+const (
+	RuleUnclosedFence = "unclosed-code-fence"
+	RuleFenceLanguage = "code-fence-language"
+	RuleFenceStyle    = "code-fence-style"
+)
+
+// FenceRules returns the built-in rules about fenced code blocks, at their
+// default severities.
+func FenceRules() []Rule {
+	return []Rule{
+		{
+			ID:          RuleUnclosedFence,
+			Description: "Every code fence is closed",
+			Severity:    SeverityError,
+			Check:       checkUnclosedFence,
+			Fix:         closeFence,
+		},
+		{
+			ID:          RuleFenceLanguage,
+			Description: "Code fences name the language of their block",
+			Severity:    SeverityWarning,
+			Check:       checkFenceLanguage,
+		},
+		{
+			ID:          RuleFenceStyle,
+			Description: "Code fences all use backticks, or all use tildes, like the first one",
+			Severity:    SeverityWarning,
+			Check:       checkFenceStyle,
+			Fix:         fixFenceStyle,
+		},
+	}
+}
+
+// checkUnclosedFence reports a code fence that is never closed, which turns
+// the rest of the document into code.
+func checkUnclosedFence(doc *Document) []Finding {
+	blocks := doc.CodeBlocks()
+	if len(blocks) == 0 || blocks[len(blocks)-1].EndLine != 0 {
+		return nil
+	}
+	b := blocks[len(blocks)-1]
+	return []Finding{{
+		Line:       b.Line,
+		Message:    fmt.Sprintf("the code fence %s is never closed, so the rest of the document is code", b.Fence),
+		Suggestion: fmt.Sprintf("close the block with a %s line", b.Fence),
+	}}
+}
+
+// closeFence closes an unclosed code fence at the end of the document,
+// where it already ends, so the document renders the same.
+func closeFence(doc *Document) string {
+	blocks := doc.CodeBlocks()
+	if len(blocks) == 0 || blocks[len(blocks)-1].EndLine != 0 {
+		return doc.Content
+	}
+	content := doc.Content
+	if !strings.HasSuffix(content, "\n") {
+		content += "\n"
+	}
+	return content + blocks[len(blocks)-1].Fence + "\n"
+}
+
+// checkFenceLanguage reports code fences without an info string naming the
+// block's language.
+func checkFenceLanguage(doc *Document) []Finding {
+	var findings []Finding
+	for _, b := range doc.CodeBlocks() {
+		if b.Language() != "" {
+			continue
+		}
+		findings = append(findings, Finding{
+			Line:       b.Line,
+			Message:    "the code fence does not name a language",
+			Suggestion: fmt.Sprintf("add the language after the fence, such as %ssh, or %stext for plain output", b.Fence, b.Fence),
+		})
+	}
+	return findings
+}
+
+// checkFenceStyle reports the code fences that use another character than
+// the first one.
+func checkFenceStyle(doc *Document) []Finding {
+	blocks := doc.CodeBlocks()
+	var findings []Finding
+	for _, b := range blocks {
+		if b.Fence[0] == blocks[0].Fence[0] {
+			continue
+		}
+		findings = append(findings, Finding{
+			Line:       b.Line,
+			Message:    fmt.Sprintf("the code fence uses %s while the first one, on line %d, uses %s", b.Fence, blocks[0].Line, blocks[0].Fence),
+			Suggestion: fmt.Sprintf("fence the block with %s", strings.Repeat(blocks[0].Fence[:1], 3)),
+		})
+	}
+	return findings
+}
+
+// fixFenceStyle rewrites the code fences that use another character than
+// the first one with that character. Fences are lengthened when the block
+// holds a line that would close the new fence, and unclosed blocks and
+// backtick fences whose info string would have a backtick are left alone.
+func fixFenceStyle(doc *Document) string {
+	blocks := doc.CodeBlocks()
+	lines := append([]string(nil), doc.Lines...)
+	for _, b := range blocks {
+		fence, ok := restyledFence(doc, b, blocks[0].Fence[0])
+		if !ok {
+			continue
+		}
+		lines[b.Line-1] = replaceFence(lines[b.Line-1], fence)
+		lines[b.EndLine-1] = replaceFence(lines[b.EndLine-1], fence)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// restyledFence returns the fence of b written with char, long enough that
+// no line of the block closes it. ok is false when b already uses char or
+// cannot be rewritten safely.
+func restyledFence(doc *Document, b CodeBlock, char byte) (fence string, ok bool) {
+	if b.Fence[0] == char || b.EndLine == 0 || (char == '`' && strings.Contains(b.Info, "`")) {
+		return "", false
+	}
+	n := len(b.Fence)
+	for _, line := range doc.Lines[b.Line : b.EndLine-1] {
+		if m := fenceOpenPattern.FindStringSubmatch(line); m != nil && m[2][0] == char && strings.TrimSpace(m[3]) == "" {
+			n = max(n, len(m[2])+1)
+		}
+	}
+	return strings.Repeat(string(char), n), true
+}
+
+// replaceFence replaces the run of fence characters of a fence line with
+// fence, keeping its indentation and info string.
+func replaceFence(line, fence string) string {
+	m := fenceOpenPattern.FindStringSubmatchIndex(line)
+	return line[:m[4]] + fence + line[m[5]:]
+}
@@ -0,0 +1,81 @@
+package lint
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFenceRules(t *testing.T) {
+	content := "# Title\n\n```go\ncode\n```\n\n~~~\nplain\n~~~\n\n```` sh\nmake\n````\n\n~~~\nunclosed"
+
+	linter, err := New(FenceRules(), nil)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	findings := linter.Check(newTestDocument(content))
+
+	tests := []struct {
+		rule  string
+		lines []int
+	}{
+		{RuleUnclosedFence, []int{15}},
+		{RuleFenceLanguage, []int{7, 15}},
+		{RuleFenceStyle, []int{7, 15}},
+	}
+	for _, tt := range tests {
+		if got := ruleLines(findings, tt.rule); !reflect.DeepEqual(got, tt.lines) {
+			t.Errorf("%s: got lines %v, want %v", tt.rule, got, tt.lines)
+		}
+	}
+}
+
+func TestFenceRulesFix(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{
+			name:    "closes an unclosed fence at the end",
+			content: "# Title\n\n```sh\nmake",
+			want:    "# Title\n\n```sh\nmake\n```\n",
+		},
+		{
+			name:    "closes an unclosed fence after the final newline",
+			content: "# Title\n\n````\ntext\n",
+			want:    "# Title\n\n````\ntext\n````\n",
+		},
+		{
+			name:    "rewrites tildes as backticks, keeping indentation and info",
+			content: "```go\na\n```\n\n  ~~~~ sh title=\"x\"\nb\n  ~~~~  \n",
+			want:    "```go\na\n```\n\n  ```` sh title=\"x\"\nb\n  ````  \n",
+		},
+		{
+			name:    "lengthens the fence over a line that would close it",
+			content: "```go\na\n```\n\n~~~md\n```\n````\n~~~\n",
+			want:    "```go\na\n```\n\n`````md\n```\n````\n`````\n",
+		},
+		{
+			name:    "rewrites backticks as tildes",
+			content: "~~~go\na\n~~~\n\n```sh\nb\n```\n",
+			want:    "~~~go\na\n~~~\n\n~~~sh\nb\n~~~\n",
+		},
+		{
+			name:    "leaves an info string with a backtick alone",
+			content: "```go\na\n```\n\n~~~ `weird`\nb\n~~~\n",
+			want:    "```go\na\n```\n\n~~~ `weird`\nb\n~~~\n",
+		},
+	}
+
+	linter, err := New(FenceRules(), nil)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := linter.Fix(newTestDocument(tt.content)).Content; got != tt.want {
+				t.Errorf("Fix() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
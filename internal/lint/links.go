@@ -0,0 +1,126 @@
+package lint
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/lpsm-dev/gtoc/internal/fsutil"
+)
+
+// RuleRelativeLinks is the ID of the rule checking relative link targets.
+const RuleRelativeLinks = "relative-links"
+
+// markdownExtensions are the extensions of the files whose anchors links
+// into them are checked against.
+var markdownExtensions = map[string]bool{".md": true, ".markdown": true, ".mdx": true}
+
+// LinkRules returns the built-in rules about the links of a document, at
+// their default severities.
+func LinkRules() []Rule {
+	return []Rule{
+		{
+			ID:          RuleRelativeLinks,
+			Description: "Relative links and images point to files that exist, and to anchors those markdown files define",
+			Severity:    SeverityWarning,
+			Check:       checkRelativeLinks,
+		},
+	}
+}
+
+// checkRelativeLinks reports every relative link or image whose file does
+// not exist next to the document, and every link to an anchor that the
+// markdown file it points into does not define. Links with a scheme,
+// protocol-relative and root-relative links are left alone.
+func checkRelativeLinks(doc *Document) []Finding {
+	dir := filepath.Dir(doc.Path)
+	anchors := map[string]map[string]int{}
+
+	var findings []Finding
+	for _, link := range doc.Links() {
+		path, fragment, ok := relativeTarget(link.Target)
+		if !ok {
+			continue
+		}
+		target := filepath.Join(dir, filepath.FromSlash(path))
+		if message, suggestion := doc.checkLinkTarget(path, target, fragment, anchors); message != "" {
+			findings = append(findings, linkFinding(doc, link, message, suggestion))
+		}
+	}
+	return findings
+}
+
+// checkLinkTarget checks a link to path, the file at target, and to
+// fragment within it when it is a markdown file. It returns "" when the link
+// is fine, or else the finding's message and suggestion. The document
+// itself need not be saved yet.
+func (d *Document) checkLinkTarget(path, target, fragment string, anchors map[string]map[string]int) (message, suggestion string) {
+	if filepath.Clean(target) != filepath.Clean(d.Path) {
+		info, err := os.Stat(target)
+		if err != nil {
+			return fmt.Sprintf("link target %s does not exist", path), "fix the path, which is relative to the document's directory, or remove the link"
+		}
+		if info.IsDir() {
+			return "", ""
+		}
+	}
+	if fragment == "" || !markdownExtensions[strings.ToLower(filepath.Ext(target))] {
+		return "", ""
+	}
+
+	targets, err := d.anchorsOf(target, anchors)
+	if err != nil {
+		return fmt.Sprintf("cannot read link target %s: %v", path, err), "check the file's permissions"
+	}
+	if _, ok := targets[fragment]; !ok {
+		return fmt.Sprintf("%s has no anchor #%s", path, fragment), "link to the anchor of one of its headings, or to the file without an anchor"
+	}
+	return "", ""
+}
+
+// relativeTarget splits a link target relative to the document into its
+// decoded path and fragment. ok is false for other targets: anchors within
+// the document, URLs with a scheme or host, and root-relative paths, which
+// depend on where the document is served from.
+func relativeTarget(target string) (path, fragment string, ok bool) {
+	if target == "" || strings.HasPrefix(target, "#") || strings.HasPrefix(target, "/") {
+		return "", "", false
+	}
+	u, err := url.Parse(target)
+	if err != nil || u.Scheme != "" || u.Host != "" || u.Opaque != "" || u.Path == "" {
+		return "", "", false
+	}
+	return u.Path, u.Fragment, true
+}
+
+// anchorsOf returns the anchors the markdown file at path defines, slugged
+// like the document's own, caching them in cache. The document's in-memory
+// content is used when it links to itself.
+func (d *Document) anchorsOf(path string, cache map[string]map[string]int) (map[string]int, error) {
+	if targets, ok := cache[path]; ok {
+		return targets, nil
+	}
+	content := d.Content
+	if filepath.Clean(path) != filepath.Clean(d.Path) {
+		text, err := fsutil.ReadText(path)
+		if err != nil {
+			return nil, err
+		}
+		content = text
+	}
+	cache[path] = d.gen.AnchorTargets(content)
+	return cache[path], nil
+}
+
+// linkFinding returns a finding at the target of link.
+func linkFinding(doc *Document, link Link, message, suggestion string) Finding {
+	return Finding{
+		Line:       link.Line,
+		Column:     utf8.RuneCountInString(doc.Lines[link.Line-1][:link.Start]) + 1,
+		Message:    message,
+		Suggestion: suggestion,
+	}
+}
@@ -0,0 +1,59 @@
+package lint
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/lpsm-dev/gtoc/internal/generator"
+)
+
+func TestRelativeLinks(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"docs/setup.md":   "# Setup\n\n## Install the CLI\n\n<a name=\"proxy\"></a>\n",
+		"docs/bom.md":     "\ufeff# Intro\r\n\r\n## Next step\r\n",
+		"images/arch.png": "png",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	content := "# Title\n\n" +
+		"[setup](docs/setup.md) [install](docs/setup.md#install-the-cli) [proxy](./docs/setup.md#proxy)\n" +
+		"![arch](images/arch.png) [docs](docs/) [site](https://example.com/missing.md) [root](/etc/missing)\n" +
+		"[gone](docs/old.md) ![diagram](images/missing%20file.png)\n" +
+		"[stale](docs/setup.md#install) [self](README.md#title) [self](README.md#nope) [code](images/arch.png#L1)\n" +
+		"[bom](docs/bom.md#intro) [crlf](docs/bom.md#next-step)\n"
+	doc := NewDocument(filepath.Join(dir, "README.md"), content, generator.NewGenerator("", 0, nil))
+
+	linter, err := New(LinkRules(), nil)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	var got []string
+	for _, f := range linter.Check(doc) {
+		got = append(got, f.Message)
+	}
+	want := []string{
+		"link target docs/old.md does not exist",
+		"link target images/missing file.png does not exist",
+		"docs/setup.md has no anchor #install",
+		"README.md has no anchor #nope",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("findings = %q, want %q", got, want)
+	}
+
+	findings := linter.Check(doc)
+	if f := findings[0]; f.Rule != RuleRelativeLinks || f.Line != 5 || f.Column != 8 {
+		t.Errorf("first finding = %+v, want %s at 5:8", f, RuleRelativeLinks)
+	}
+}
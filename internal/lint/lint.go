@@ -0,0 +1,216 @@
+// Package lint checks the structure of markdown documents against a set of
+// rules, each with an ID and a severity, and applies the fixes of the rules
+// that offer one.
+package lint
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/lpsm-dev/gtoc/internal/generator"
+)
+
+// Severity is how serious a rule's findings are.
+type Severity string
+
+// The supported severities. A rule at SeverityOff is disabled.
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+	SeverityInfo    Severity = "info"
+	SeverityOff     Severity = "off"
+)
+
+// Finding is one problem a rule found in a document. Line and Column are
+// 1-based; a zero Column stands for the start of the line. Suggestion tells
+// how to fix the problem by hand.
+type Finding struct {
+	Rule       string
+	Severity   Severity
+	Line       int
+	Column     int
+	Message    string
+	Suggestion string
+	Fixable    bool
+}
+
+// Rule is a single check. Check returns the rule's findings, whose Rule,
+// Severity and Fixable fields are filled in by the Linter. Fix, when set,
+// returns the document content with the problems corrected.
+type Rule struct {
+	ID          string
+	Description string
+	Severity    Severity
+	Check       func(doc *Document) []Finding
+	Fix         func(doc *Document) string
+}
+
+// Document is a markdown document being linted.
+type Document struct {
+	Path    string
+	Content string
+	Lines   []string
+	// Headings lists every heading outside code fences and the TOC block.
+	Headings []*generator.Heading
+
+	gen *generator.Generator
+	// toc renders the table of contents fixes insert, and updateTOC returns
+	// content with its table of contents brought up to date.
+	toc       *generator.Generator
+	updateTOC func(content string) string
+}
+
+// DocumentOption configures optional Document behavior.
+type DocumentOption func(*Document)
+
+// WithTOC sets how the document's table of contents is generated: gen
+// renders the one fixes insert into a document without one, and update
+// returns content with its table of contents brought up to date. Both
+// default to the document's heading generator, which lists every heading.
+func WithTOC(gen *generator.Generator, update func(content string) string) DocumentOption {
+	return func(d *Document) {
+		d.toc = gen
+		d.updateTOC = update
+	}
+}
+
+// NewDocument prepares content, the text of the file at path, for linting.
+// gen parses the headings and should not filter them; see WithTOC for a
+// table of contents that does.
+func NewDocument(path, content string, gen *generator.Generator, opts ...DocumentOption) *Document {
+	d := &Document{
+		Path: path,
+		gen:  gen,
+		toc:  gen,
+		updateTOC: func(content string) string {
+			return gen.GetFileWithUpdatedTOC(content, gen.GenerateFromContent(content))
+		},
+	}
+	for _, opt := range opts {
+		opt(d)
+	}
+	return d.withContent(content)
+}
+
+// withContent returns a copy of the document holding content instead.
+func (d *Document) withContent(content string) *Document {
+	updated := *d
+	updated.Content = content
+	updated.Lines = strings.Split(content, "\n")
+	updated.Headings = d.gen.ParseHeadings(content)
+	return &updated
+}
+
+// RefreshTOC returns the document with its table of contents brought up to
+// date, or doc itself when it has none or it is up to date.
+func (d *Document) RefreshTOC() *Document {
+	if !generator.HasTOC(d.Content) {
+		return d
+	}
+	updated := d.updateTOC(d.Content)
+	if updated == d.Content {
+		return d
+	}
+	return d.withContent(updated)
+}
+
+// Linter runs a set of rules at their configured severities.
+type Linter struct {
+	rules []Rule
+}
+
+// New returns a Linter running rules, with the severity of each rule
+// overridden by severities, keyed by rule ID. An unknown rule ID or
+// severity is an error.
+func New(rules []Rule, severities map[string]string) (*Linter, error) {
+	byID := map[string]int{}
+	configured := make([]Rule, len(rules))
+	for i, r := range rules {
+		byID[r.ID] = i
+		configured[i] = r
+	}
+
+	for id, value := range severities {
+		i, ok := byID[id]
+		if !ok {
+			return nil, fmt.Errorf("unknown rule %q", id)
+		}
+		severity, err := ParseSeverity(value)
+		if err != nil {
+			return nil, fmt.Errorf("rule %q: %w", id, err)
+		}
+		configured[i].Severity = severity
+	}
+	return &Linter{rules: configured}, nil
+}
+
+// ParseSeverity returns the Severity named s.
+func ParseSeverity(s string) (Severity, error) {
+	switch severity := Severity(strings.ToLower(s)); severity {
+	case SeverityError, SeverityWarning, SeverityInfo, SeverityOff:
+		return severity, nil
+	default:
+		return "", fmt.Errorf("unknown severity %q (use error, warning, info or off)", s)
+	}
+}
+
+// Rules returns the linter's rules at their configured severities.
+func (l *Linter) Rules() []Rule {
+	return l.rules
+}
+
+// Check runs every enabled rule on doc and returns the findings ordered by
+// line.
+func (l *Linter) Check(doc *Document) []Finding {
+	var findings []Finding
+	for _, r := range l.rules {
+		findings = append(findings, l.check(r, doc)...)
+	}
+	sort.SliceStable(findings, func(i, j int) bool {
+		if findings[i].Line != findings[j].Line {
+			return findings[i].Line < findings[j].Line
+		}
+		return findings[i].Column < findings[j].Column
+	})
+	return findings
+}
+
+// check runs a single rule if it is enabled.
+func (l *Linter) check(r Rule, doc *Document) []Finding {
+	if r.Severity == SeverityOff {
+		return nil
+	}
+	findings := r.Check(doc)
+	for i := range findings {
+		findings[i].Rule = r.ID
+		findings[i].Severity = r.Severity
+		findings[i].Fixable = r.Fix != nil
+	}
+	return findings
+}
+
+// Fix applies, in rule order, the fix of every enabled rule that has
+// findings in doc and returns the fixed document.
+func (l *Linter) Fix(doc *Document) *Document {
+	for _, r := range l.rules {
+		if r.Fix == nil || len(l.check(r, doc)) == 0 {
+			continue
+		}
+		if fixed := r.Fix(doc); fixed != doc.Content {
+			doc = doc.withContent(fixed)
+		}
+	}
+	return doc
+}
+
+// Count returns how many findings have the given severity.
+func Count(findings []Finding, severity Severity) int {
+	n := 0
+	for _, f := range findings {
+		if f.Severity == severity {
+			n++
+		}
+	}
+	return n
+}
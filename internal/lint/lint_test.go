@@ -0,0 +1,162 @@
+package lint
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/lpsm-dev/gtoc/internal/generator"
+)
+
+func newTestDocument(content string) *Document {
+	return NewDocument("README.md", content, generator.NewGenerator("", 0, nil))
+}
+
+// ruleLines returns the lines of the findings of rule, in order.
+func ruleLines(findings []Finding, rule string) []int {
+	var lines []int
+	for _, f := range findings {
+		if f.Rule == rule {
+			lines = append(lines, f.Line)
+		}
+	}
+	return lines
+}
+
+func TestStructureRules(t *testing.T) {
+	content := `# Title
+
+Intro.
+
+### Skipped level
+
+text
+
+## Setup:
+
+## Setup
+
+text
+
+# Second title
+
+` + "```\n# not a heading\n```\n"
+
+	linter, err := New(StructureRules(), map[string]string{RuleSingleH1: "warning"})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	findings := linter.Check(newTestDocument(content))
+
+	tests := []struct {
+		rule  string
+		lines []int
+	}{
+		{RuleHeadingIncrement, []int{5}},
+		{RuleSingleH1, []int{15}},
+		{RuleDuplicateSibling, nil},
+		{RuleEmptySection, []int{9}},
+		{RuleTrailingPunctuation, []int{9}},
+		{RuleTOCMarkers, []int{1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.rule, func(t *testing.T) {
+			got := ruleLines(findings, tt.rule)
+			if len(got) != len(tt.lines) {
+				t.Fatalf("lines = %v, want %v", got, tt.lines)
+			}
+			for i := range got {
+				if got[i] != tt.lines[i] {
+					t.Errorf("lines = %v, want %v", got, tt.lines)
+				}
+			}
+		})
+	}
+}
+
+func TestCheckDuplicateSiblings(t *testing.T) {
+	content := "# A\n\n## Usage\n\n### Example\n\n## Install\n\n### Example\n\n## usage\n"
+	got := ruleLines(mustCheck(t, content), RuleDuplicateSibling)
+	if len(got) != 1 || got[0] != 11 {
+		t.Errorf("only the repeated sibling should be reported, got lines %v", got)
+	}
+}
+
+func TestCheckTrailingPunctuationAllowsQuestionsAndEntities(t *testing.T) {
+	content := "# Why gtoc?\n\ntext\n\n# Tom &amp;\n\ntext\n"
+	if got := ruleLines(mustCheck(t, content), RuleTrailingPunctuation); len(got) != 0 {
+		t.Errorf("questions and entities should be allowed, got lines %v", got)
+	}
+}
+
+func TestCheckTrailingPunctuationColumn(t *testing.T) {
+	for _, f := range mustCheck(t, "# Título final!!\n\ntext\n") {
+		if f.Rule != RuleTrailingPunctuation {
+			continue
+		}
+		if f.Column != 15 {
+			t.Errorf("column = %d, want 15, the first trailing punctuation rune", f.Column)
+		}
+		if f.Suggestion != `rename it to "Título final"` {
+			t.Errorf("suggestion = %q", f.Suggestion)
+		}
+		return
+	}
+	t.Error("expected a trailing punctuation finding")
+}
+
+func mustCheck(t *testing.T, content string) []Finding {
+	t.Helper()
+	linter, err := New(StructureRules(), nil)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	return linter.Check(newTestDocument(content))
+}
+
+func TestLinterFix(t *testing.T) {
+	content := "# Title\n\nIntro.\n\n## Install:\n\ntext\n\n## Usage!\n\ntext\n"
+	linter, err := New(StructureRules(), nil)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	fixed := linter.Fix(newTestDocument(content))
+	for _, want := range []string{"## Install\n", "## Usage\n", "<!-- START_TABLE_OF_CONTENTS -->", "[Install](#install)"} {
+		if !strings.Contains(fixed.Content, want) {
+			t.Errorf("fixed content should contain %q, got:\n%s", want, fixed.Content)
+		}
+	}
+	if strings.Index(fixed.Content, "START_TABLE_OF_CONTENTS") < strings.Index(fixed.Content, "# Title") {
+		t.Errorf("the TOC should go below the single title, got:\n%s", fixed.Content)
+	}
+	if findings := linter.Check(fixed); len(findings) != 0 {
+		t.Errorf("a fixed document should have no findings, got %+v", findings)
+	}
+	if again := linter.Fix(fixed); again.Content != fixed.Content {
+		t.Errorf("fixing should be idempotent:\nfirst:\n%s\nsecond:\n%s", fixed.Content, again.Content)
+	}
+}
+
+func TestLinterSkipsDisabledRules(t *testing.T) {
+	linter, err := New(StructureRules(), map[string]string{RuleTrailingPunctuation: "off", RuleTOCMarkers: "OFF"})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	doc := newTestDocument("# Title:\n\ntext\n")
+	if findings := linter.Check(doc); len(findings) != 0 {
+		t.Errorf("disabled rules should report nothing, got %+v", findings)
+	}
+	if fixed := linter.Fix(doc); fixed.Content != doc.Content {
+		t.Errorf("disabled rules should fix nothing, got:\n%s", fixed.Content)
+	}
+}
+
+func TestNewRejectsInvalidConfig(t *testing.T) {
+	if _, err := New(StructureRules(), map[string]string{"no-such-rule": "error"}); err == nil {
+		t.Error("expected an error for an unknown rule")
+	}
+	if _, err := New(StructureRules(), map[string]string{RuleEmptySection: "fatal"}); err == nil {
+		t.Error("expected an error for an unknown severity")
+	}
+}
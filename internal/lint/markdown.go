@@ -0,0 +1,180 @@
+package lint
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/lpsm-dev/gtoc/internal/generator"
+)
+
+// fenceOpenPattern matches the opening line of a fenced code block: up to
+// three spaces, a run of three or more backticks or tildes, and the info
+// string. Backtick fences cannot have backticks in their info string.
+var fenceOpenPattern = regexp.MustCompile("^( {0,3})(`{3,}|~{3,})(.*)$")
+
+// markdownImagePattern matches an inline or reference markdown image,
+// capturing its alt text and its destination or label.
+var markdownImagePattern = regexp.MustCompile(`!\[([^\]]*)\](?:\(\s*<?([^)\s>]*)>?[^)]*\)|\[([^\]]*)\])`)
+
+// htmlImagePattern matches an HTML img tag.
+var htmlImagePattern = regexp.MustCompile(`(?i)<img\b[^>]*>`)
+
+// htmlAttrPattern matches an attribute of an HTML tag, capturing its name
+// and its quoted value.
+var htmlAttrPattern = regexp.MustCompile(`(?i)\b([a-z-]+)\s*=\s*(?:"([^"]*)"|'([^']*)')`)
+
+// linkTargetPatterns match the target of a link or image in a line, in
+// its first group: inline markdown ("](target)"), a reference definition
+// ("[label]: target") and an HTML href or src attribute.
+var linkTargetPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`\]\(\s*<?([^)\s>]+)`),
+	regexp.MustCompile(`^ {0,3}\[[^\]]+\]:\s*<?([^\s>]+)`),
+	regexp.MustCompile(`(?i)\b(?:href|src)\s*=\s*"([^"]*)"`),
+	regexp.MustCompile(`(?i)\b(?:href|src)\s*=\s*'([^']*)'`),
+}
+
+// inlineCodePattern matches a code span, whose content is not markdown.
+var inlineCodePattern = regexp.MustCompile("(`+)[^`]*?(`+)")
+
+// CodeBlock is a fenced code block. Line and EndLine are the 1-based lines
+// of its opening and closing fences; EndLine is 0 when the block is never
+// closed and runs to the end of the document.
+type CodeBlock struct {
+	Line    int
+	EndLine int
+	// Fence is the opening run of backticks or tildes, such as "```".
+	Fence string
+	// Info is the trimmed text after the opening fence, whose first word
+	// is the language.
+	Info string
+}
+
+// Language returns the language of the block, the first word of its info
+// string, or "" when it has none.
+func (b CodeBlock) Language() string {
+	if fields := strings.Fields(b.Info); len(fields) > 0 {
+		return fields[0]
+	}
+	return ""
+}
+
+// Image is an image in a document, written in markdown or as an HTML img
+// tag. Line is 1-based.
+type Image struct {
+	Line int
+	Alt  string
+	Src  string
+}
+
+// Link is the target of a link or image in a document, as written. Line is
+// 1-based and Start is the byte offset of the target within the line.
+type Link struct {
+	Line   int
+	Start  int
+	Target string
+}
+
+// CodeBlocks returns the document's fenced code blocks in order.
+func (d *Document) CodeBlocks() []CodeBlock {
+	var blocks []CodeBlock
+	var open *CodeBlock
+	for i, line := range d.Lines {
+		if open != nil {
+			if closesFence(line, open.Fence) {
+				open.EndLine = i + 1
+				blocks = append(blocks, *open)
+				open = nil
+			}
+			continue
+		}
+		m := fenceOpenPattern.FindStringSubmatch(line)
+		if m == nil || (m[2][0] == '`' && strings.Contains(m[3], "`")) {
+			continue
+		}
+		open = &CodeBlock{Line: i + 1, Fence: m[2], Info: strings.TrimSpace(m[3])}
+	}
+	if open != nil {
+		blocks = append(blocks, *open)
+	}
+	return blocks
+}
+
+// closesFence reports whether line closes a block opened with fence: a run
+// of the same character at least as long, with nothing after it.
+func closesFence(line, fence string) bool {
+	m := fenceOpenPattern.FindStringSubmatch(line)
+	return m != nil && m[2][0] == fence[0] && len(m[2]) >= len(fence) && strings.TrimSpace(m[3]) == ""
+}
+
+// Images returns the images outside code blocks and code spans, in order.
+// The Src of a reference image is its label.
+func (d *Document) Images() []Image {
+	var images []Image
+	d.eachProseLine(func(n int, line string) {
+		for _, m := range markdownImagePattern.FindAllStringSubmatch(line, -1) {
+			images = append(images, Image{Line: n, Alt: strings.TrimSpace(m[1]), Src: m[2] + m[3]})
+		}
+		for _, tag := range htmlImagePattern.FindAllString(line, -1) {
+			attrs := map[string]string{}
+			for _, a := range htmlAttrPattern.FindAllStringSubmatch(tag, -1) {
+				attrs[strings.ToLower(a[1])] = a[2] + a[3]
+			}
+			images = append(images, Image{Line: n, Alt: strings.TrimSpace(attrs["alt"]), Src: attrs["src"]})
+		}
+	})
+	return images
+}
+
+// Links returns the targets of the links and images outside code blocks and
+// code spans, in the order they appear.
+func (d *Document) Links() []Link {
+	var links []Link
+	d.eachProseLine(func(n int, line string) {
+		var found []Link
+		for _, pattern := range linkTargetPatterns {
+			for _, m := range pattern.FindAllStringSubmatchIndex(line, -1) {
+				found = append(found, Link{Line: n, Start: m[2], Target: line[m[2]:m[3]]})
+			}
+		}
+		sort.Slice(found, func(i, j int) bool { return found[i].Start < found[j].Start })
+		links = append(links, found...)
+	})
+	return links
+}
+
+// eachProseLine calls fn with the 1-based number and the text of every line
+// outside fenced code blocks, with its code spans blanked out by spaces so
+// offsets within the line stay the same.
+func (d *Document) eachProseLine(fn func(n int, line string)) {
+	inBlock := make([]bool, len(d.Lines))
+	for _, b := range d.CodeBlocks() {
+		end := b.EndLine
+		if end == 0 {
+			end = len(d.Lines)
+		}
+		for i := b.Line - 1; i < end; i++ {
+			inBlock[i] = true
+		}
+	}
+	for i, line := range d.Lines {
+		if !inBlock[i] {
+			fn(i+1, inlineCodePattern.ReplaceAllStringFunc(line, func(span string) string {
+				return strings.Repeat(" ", len(span))
+			}))
+		}
+	}
+}
+
+// AnchorLinks returns the document's links to anchors in itself, and the
+// ones among them that no heading or HTML anchor defines.
+func (d *Document) AnchorLinks() (links, broken []generator.AnchorLink) {
+	links = generator.FindAnchorLinks(d.Content)
+	targets := d.gen.AnchorTargets(d.Content)
+	for _, link := range links {
+		if _, ok := targets[link.Anchor]; !ok {
+			broken = append(broken, link)
+		}
+	}
+	return links, broken
+}
@@ -0,0 +1,66 @@
+package lint
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCodeBlocks(t *testing.T) {
+	content := "# Title\n\n```go\nfmt.Println()\n```\n\n~~~~\n```\nnot a fence\n~~~~\n\n  ```sh title=\"run\"\nmake\n```\n\n``` `not` a fence\n\n```\nunclosed\n"
+	want := []CodeBlock{
+		{Line: 3, EndLine: 5, Fence: "```", Info: "go"},
+		{Line: 7, EndLine: 10, Fence: "~~~~"},
+		{Line: 12, EndLine: 14, Fence: "```", Info: `sh title="run"`},
+		{Line: 18, Fence: "```"},
+	}
+	got := newTestDocument(content).CodeBlocks()
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("CodeBlocks() = %+v, want %+v", got, want)
+	}
+	if lang := got[2].Language(); lang != "sh" {
+		t.Errorf("Language() = %q, want %q", lang, "sh")
+	}
+}
+
+func TestImages(t *testing.T) {
+	content := "# Title\n\n![Logo](logo.png \"The logo\") and ![](badge.svg)\n" +
+		"<img src=\"shot.png\" alt='Screenshot'> <IMG SRC=\"raw.png\">\n" +
+		"![Diagram][arch] `![code](span.png)`\n\n```md\n![inside](fence.png)\n```\n"
+	want := []Image{
+		{Line: 3, Alt: "Logo", Src: "logo.png"},
+		{Line: 3, Src: "badge.svg"},
+		{Line: 4, Alt: "Screenshot", Src: "shot.png"},
+		{Line: 4, Src: "raw.png"},
+		{Line: 5, Alt: "Diagram", Src: "arch"},
+	}
+	if got := newTestDocument(content).Images(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Images() = %+v, want %+v", got, want)
+	}
+}
+
+func TestAnchorLinks(t *testing.T) {
+	content := "<a name=\"readme-top\"></a>\n\n# Title\n\nSee [usage](#usage), [title](#title) and [top](#readme-top).\n\n```md\n[ignored](#nowhere)\n```\n"
+	links, broken := newTestDocument(content).AnchorLinks()
+	if len(links) != 3 {
+		t.Errorf("got %d anchor links, want 3", len(links))
+	}
+	if len(broken) != 1 || broken[0].Anchor != "usage" || broken[0].Line != 5 {
+		t.Errorf("broken = %+v, want the link to #usage on line 5", broken)
+	}
+}
+
+func TestLinks(t *testing.T) {
+	content := "# Title\n\n[![Badge](badge.svg)](https://ci.example.com) [setup](<docs/setup.md#install> \"Setup\")\n" +
+		"<a href=\"#top\"><img src='logo.png'></a> `[code](span.md)`\n\n[ref]: docs/ref.md\n\n```md\n[fenced](fenced.md)\n```\n"
+	want := []Link{
+		{Line: 3, Start: 10, Target: "badge.svg"},
+		{Line: 3, Start: 22, Target: "https://ci.example.com"},
+		{Line: 3, Start: 55, Target: "docs/setup.md#install"},
+		{Line: 4, Start: 9, Target: "#top"},
+		{Line: 4, Start: 25, Target: "logo.png"},
+		{Line: 6, Start: 7, Target: "docs/ref.md"},
+	}
+	if got := newTestDocument(content).Links(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Links() = %+v, want %+v", got, want)
+	}
+}
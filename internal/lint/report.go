@@ -0,0 +1,292 @@
+package lint
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+)
+
+// Format is an output format for findings.
+type Format string
+
+// The supported report formats.
+const (
+	FormatText       Format = "text"
+	FormatJSON       Format = "json"
+	FormatSARIF      Format = "sarif"
+	FormatCheckstyle Format = "checkstyle"
+	FormatGitHub     Format = "github"
+)
+
+// informationURI is the project page SARIF reports link the tool to.
+const informationURI = "https://github.com/lpsm-dev/gtoc"
+
+// ParseFormat returns the Format named s.
+func ParseFormat(s string) (Format, error) {
+	switch format := Format(strings.ToLower(s)); format {
+	case FormatText, FormatJSON, FormatSARIF, FormatCheckstyle, FormatGitHub:
+		return format, nil
+	default:
+		return "", fmt.Errorf("unknown format %q (use text, json, sarif, checkstyle or github)", s)
+	}
+}
+
+// WriteReport writes the findings in the file at path to w in format. rules
+// are the rules that ran, which SARIF reports describe.
+func WriteReport(w io.Writer, format Format, path string, findings []Finding, rules []Rule) error {
+	switch format {
+	case FormatJSON:
+		return writeJSON(w, path, findings)
+	case FormatSARIF:
+		return writeSARIF(w, path, findings, rules)
+	case FormatCheckstyle:
+		return writeCheckstyle(w, path, findings)
+	case FormatGitHub:
+		return writeGitHub(w, path, findings)
+	default:
+		return writeText(w, path, findings)
+	}
+}
+
+// column returns the 1-based column of f, the start of the line when it
+// has none.
+func column(f Finding) int {
+	if f.Column < 1 {
+		return 1
+	}
+	return f.Column
+}
+
+// writeText writes one "path:line:column: severity: message (rule)" line per
+// finding.
+func writeText(w io.Writer, path string, findings []Finding) error {
+	for _, f := range findings {
+		if _, err := fmt.Fprintf(w, "%s:%d:%d: %s: %s (%s)\n", path, f.Line, column(f), f.Severity, f.Message, f.Rule); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// jsonFinding is a finding in a JSON report.
+type jsonFinding struct {
+	File       string   `json:"file"`
+	Line       int      `json:"line"`
+	Column     int      `json:"column"`
+	Rule       string   `json:"rule"`
+	Severity   Severity `json:"severity"`
+	Message    string   `json:"message"`
+	Suggestion string   `json:"suggestion,omitempty"`
+	Fixable    bool     `json:"fixable"`
+}
+
+// writeJSON writes the findings as a JSON array.
+func writeJSON(w io.Writer, path string, findings []Finding) error {
+	out := make([]jsonFinding, len(findings))
+	for i, f := range findings {
+		out[i] = jsonFinding{
+			File:       path,
+			Line:       f.Line,
+			Column:     column(f),
+			Rule:       f.Rule,
+			Severity:   f.Severity,
+			Message:    f.Message,
+			Suggestion: f.Suggestion,
+			Fixable:    f.Fixable,
+		}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
+
+// SARIF 2.1.0 log, limited to the properties gtoc fills in.
+type (
+	sarifLog struct {
+		Schema  string     `json:"$schema"`
+		Version string     `json:"version"`
+		Runs    []sarifRun `json:"runs"`
+	}
+	sarifRun struct {
+		Tool    sarifTool     `json:"tool"`
+		Results []sarifResult `json:"results"`
+	}
+	sarifTool struct {
+		Driver sarifDriver `json:"driver"`
+	}
+	sarifDriver struct {
+		Name           string      `json:"name"`
+		InformationURI string      `json:"informationUri"`
+		Rules          []sarifRule `json:"rules"`
+	}
+	sarifRule struct {
+		ID               string       `json:"id"`
+		ShortDescription sarifMessage `json:"shortDescription"`
+		DefaultConfig    sarifConfig  `json:"defaultConfiguration"`
+	}
+	sarifConfig struct {
+		Level string `json:"level"`
+	}
+	sarifMessage struct {
+		Text string `json:"text"`
+	}
+	sarifResult struct {
+		RuleID     string            `json:"ruleId"`
+		Level      string            `json:"level"`
+		Message    sarifMessage      `json:"message"`
+		Locations  []sarifLocation   `json:"locations"`
+		Properties map[string]string `json:"properties,omitempty"`
+	}
+	sarifLocation struct {
+		PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+	}
+	sarifPhysicalLocation struct {
+		ArtifactLocation sarifArtifact `json:"artifactLocation"`
+		Region           sarifRegion   `json:"region"`
+	}
+	sarifArtifact struct {
+		URI string `json:"uri"`
+	}
+	sarifRegion struct {
+		StartLine   int `json:"startLine"`
+		StartColumn int `json:"startColumn"`
+	}
+)
+
+// sarifLevel maps a severity to a SARIF result level.
+func sarifLevel(s Severity) string {
+	switch s {
+	case SeverityError:
+		return "error"
+	case SeverityWarning:
+		return "warning"
+	case SeverityOff:
+		return "none"
+	default:
+		return "note"
+	}
+}
+
+// writeSARIF writes the findings as a SARIF 2.1.0 log with a single run.
+// The suggested fix goes in the "suggestion" property of each result.
+func writeSARIF(w io.Writer, path string, findings []Finding, rules []Rule) error {
+	driver := sarifDriver{Name: "gtoc", InformationURI: informationURI, Rules: []sarifRule{}}
+	for _, r := range rules {
+		driver.Rules = append(driver.Rules, sarifRule{
+			ID:               r.ID,
+			ShortDescription: sarifMessage{Text: r.Description},
+			DefaultConfig:    sarifConfig{Level: sarifLevel(r.Severity)},
+		})
+	}
+
+	results := []sarifResult{}
+	for _, f := range findings {
+		result := sarifResult{
+			RuleID:  f.Rule,
+			Level:   sarifLevel(f.Severity),
+			Message: sarifMessage{Text: f.Message},
+			Locations: []sarifLocation{{PhysicalLocation: sarifPhysicalLocation{
+				ArtifactLocation: sarifArtifact{URI: filepath.ToSlash(path)},
+				Region:           sarifRegion{StartLine: f.Line, StartColumn: column(f)},
+			}}},
+		}
+		if f.Suggestion != "" {
+			result.Properties = map[string]string{"suggestion": f.Suggestion}
+		}
+		results = append(results, result)
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(sarifLog{
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Version: "2.1.0",
+		Runs:    []sarifRun{{Tool: sarifTool{Driver: driver}, Results: results}},
+	})
+}
+
+// Checkstyle XML report.
+type (
+	checkstyleReport struct {
+		XMLName xml.Name         `xml:"checkstyle"`
+		Version string           `xml:"version,attr"`
+		Files   []checkstyleFile `xml:"file"`
+	}
+	checkstyleFile struct {
+		Name   string            `xml:"name,attr"`
+		Errors []checkstyleError `xml:"error"`
+	}
+	checkstyleError struct {
+		Line     int    `xml:"line,attr"`
+		Column   int    `xml:"column,attr"`
+		Severity string `xml:"severity,attr"`
+		Message  string `xml:"message,attr"`
+		Source   string `xml:"source,attr"`
+	}
+)
+
+// writeCheckstyle writes the findings as a checkstyle XML report. The
+// suggested fix is appended to each message.
+func writeCheckstyle(w io.Writer, path string, findings []Finding) error {
+	file := checkstyleFile{Name: path}
+	for _, f := range findings {
+		file.Errors = append(file.Errors, checkstyleError{
+			Line:     f.Line,
+			Column:   column(f),
+			Severity: string(f.Severity),
+			Message:  withSuggestion(f),
+			Source:   "gtoc." + f.Rule,
+		})
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(checkstyleReport{Version: "4.3", Files: []checkstyleFile{file}}); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// writeGitHub writes the findings as GitHub Actions workflow commands, which
+// show up as annotations on the file. Info findings become notices.
+func writeGitHub(w io.Writer, path string, findings []Finding) error {
+	for _, f := range findings {
+		command := "notice"
+		if f.Severity == SeverityError || f.Severity == SeverityWarning {
+			command = string(f.Severity)
+		}
+		_, err := fmt.Fprintf(w, "::%s file=%s,line=%d,col=%d,title=%s::%s\n",
+			command, escapeGitHubProperty(filepath.ToSlash(path)), f.Line, column(f),
+			escapeGitHubProperty(f.Rule), escapeGitHubData(withSuggestion(f)))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// withSuggestion returns the finding's message followed by its suggested
+// fix, if any.
+func withSuggestion(f Finding) string {
+	if f.Suggestion == "" {
+		return f.Message
+	}
+	return f.Message + " (suggestion: " + f.Suggestion + ")"
+}
+
+// escapeGitHubData escapes the message of a workflow command.
+func escapeGitHubData(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(s)
+}
+
+// escapeGitHubProperty escapes a property value of a workflow command.
+func escapeGitHubProperty(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C").Replace(s)
+}
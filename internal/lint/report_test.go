@@ -0,0 +1,131 @@
+package lint
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"strings"
+	"testing"
+)
+
+func reportFindings() []Finding {
+	return []Finding{
+		{Rule: RuleTrailingPunctuation, Severity: SeverityWarning, Line: 3, Column: 9, Message: `heading "Setup:" ends with punctuation`, Suggestion: `rename it to "Setup"`, Fixable: true},
+		{Rule: RuleHeadingIncrement, Severity: SeverityError, Line: 5, Message: "heading level skips from h1 to h3"},
+		{Rule: RuleEmptySection, Severity: SeverityInfo, Line: 7, Message: "section \"50%, done\" is empty"},
+	}
+}
+
+func writeTestReport(t *testing.T, format Format) string {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := WriteReport(&buf, format, "docs/README.md", reportFindings(), StructureRules()); err != nil {
+		t.Fatalf("WriteReport(%s) failed: %v", format, err)
+	}
+	return buf.String()
+}
+
+func TestWriteReportText(t *testing.T) {
+	got := writeTestReport(t, FormatText)
+	want := "docs/README.md:3:9: warning: heading \"Setup:\" ends with punctuation (heading-trailing-punctuation)\n" +
+		"docs/README.md:5:1: error: heading level skips from h1 to h3 (heading-increment)\n"
+	if !strings.HasPrefix(got, want) {
+		t.Errorf("text report =\n%s\nwant prefix\n%s", got, want)
+	}
+}
+
+func TestWriteReportJSON(t *testing.T) {
+	var got []jsonFinding
+	if err := json.Unmarshal([]byte(writeTestReport(t, FormatJSON)), &got); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if len(got) != 3 {
+		t.Fatalf("got %d findings, want 3", len(got))
+	}
+	want := jsonFinding{File: "docs/README.md", Line: 3, Column: 9, Rule: RuleTrailingPunctuation, Severity: SeverityWarning,
+		Message: `heading "Setup:" ends with punctuation`, Suggestion: `rename it to "Setup"`, Fixable: true}
+	if got[0] != want {
+		t.Errorf("first finding = %+v, want %+v", got[0], want)
+	}
+	if got[1].Column != 1 {
+		t.Errorf("a finding without a column should start at column 1, got %d", got[1].Column)
+	}
+}
+
+// writeSARIFRun writes the test report as SARIF and returns its only run.
+func writeSARIFRun(t *testing.T) sarifRun {
+	t.Helper()
+	var log sarifLog
+	if err := json.Unmarshal([]byte(writeTestReport(t, FormatSARIF)), &log); err != nil {
+		t.Fatalf("invalid SARIF: %v", err)
+	}
+	if log.Version != "2.1.0" || len(log.Runs) != 1 {
+		t.Fatalf("unexpected SARIF log: %+v", log)
+	}
+	return log.Runs[0]
+}
+
+func TestWriteReportSARIF(t *testing.T) {
+	run := writeSARIFRun(t)
+	if len(run.Tool.Driver.Rules) != len(StructureRules()) {
+		t.Errorf("driver should describe every rule, got %d", len(run.Tool.Driver.Rules))
+	}
+	levels := []string{"warning", "error", "note"}
+	for i, r := range run.Results {
+		if r.Level != levels[i] {
+			t.Errorf("result %d level = %q, want %q", i, r.Level, levels[i])
+		}
+	}
+}
+
+func TestWriteReportSARIFLocation(t *testing.T) {
+	first := writeSARIFRun(t).Results[0]
+	region := first.Locations[0].PhysicalLocation.Region
+	if first.Locations[0].PhysicalLocation.ArtifactLocation.URI != "docs/README.md" || region.StartLine != 3 || region.StartColumn != 9 {
+		t.Errorf("unexpected location: %+v", first.Locations[0])
+	}
+	if first.Properties["suggestion"] != `rename it to "Setup"` {
+		t.Errorf("suggestion = %q", first.Properties["suggestion"])
+	}
+}
+
+func TestWriteReportCheckstyle(t *testing.T) {
+	var report checkstyleReport
+	if err := xml.Unmarshal([]byte(writeTestReport(t, FormatCheckstyle)), &report); err != nil {
+		t.Fatalf("invalid checkstyle XML: %v", err)
+	}
+	if len(report.Files) != 1 || report.Files[0].Name != "docs/README.md" || len(report.Files[0].Errors) != 3 {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+	want := checkstyleError{Line: 3, Column: 9, Severity: "warning",
+		Message: `heading "Setup:" ends with punctuation (suggestion: rename it to "Setup")`, Source: "gtoc.heading-trailing-punctuation"}
+	if got := report.Files[0].Errors[0]; got != want {
+		t.Errorf("first error = %+v, want %+v", got, want)
+	}
+}
+
+func TestWriteReportGitHub(t *testing.T) {
+	lines := strings.Split(strings.TrimSuffix(writeTestReport(t, FormatGitHub), "\n"), "\n")
+	want := []string{
+		`::warning file=docs/README.md,line=3,col=9,title=heading-trailing-punctuation::heading "Setup:" ends with punctuation (suggestion: rename it to "Setup")`,
+		`::error file=docs/README.md,line=5,col=1,title=heading-increment::heading level skips from h1 to h3`,
+		`::notice file=docs/README.md,line=7,col=1,title=empty-section::section "50%25, done" is empty`,
+	}
+	if len(lines) != len(want) {
+		t.Fatalf("got %d lines, want %d:\n%s", len(lines), len(want), strings.Join(lines, "\n"))
+	}
+	for i := range want {
+		if lines[i] != want[i] {
+			t.Errorf("line %d =\n%s\nwant\n%s", i, lines[i], want[i])
+		}
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	if got, err := ParseFormat("SARIF"); err != nil || got != FormatSARIF {
+		t.Errorf("ParseFormat(SARIF) = %q, %v", got, err)
+	}
+	if _, err := ParseFormat("xml"); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+}
@@ -0,0 +1,258 @@
+package lint
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/lpsm-dev/gtoc/internal/generator"
+)
+
+// IDs of the structure rules.
+const (
+	RuleHeadingIncrement    = "heading-increment"
+	RuleSingleH1            = "single-h1"
+	RuleDuplicateSibling    = "duplicate-sibling-heading"
+	RuleEmptySection        = "empty-section"
+	RuleTrailingPunctuation = "heading-trailing-punctuation"
+	RuleTOCMarkers          = "toc-markers"
+)
+
+// trailingPunctuation lists the characters a heading should not end with.
+// Question marks are allowed, since FAQ headings are questions.
+const trailingPunctuation = ".,;:!。，；：！"
+
+// trailingEntityPattern matches an HTML entity such as "&amp;" at the end of
+// a heading, whose ";" is not punctuation.
+var trailingEntityPattern = regexp.MustCompile(`&#?\w+;$`)
+
+// StructureRules returns the built-in rules about a document's heading
+// structure, at their default severities. single-h1 is off by default
+// because analyze lays READMEs out as several top-level sections.
+func StructureRules() []Rule {
+	return []Rule{
+		{
+			ID:          RuleHeadingIncrement,
+			Description: "Heading levels increase one at a time",
+			Severity:    SeverityWarning,
+			Check:       checkHeadingIncrement,
+		},
+		{
+			ID:          RuleSingleH1,
+			Description: "The document has a single top-level heading",
+			Severity:    SeverityOff,
+			Check:       checkSingleH1,
+		},
+		{
+			ID:          RuleDuplicateSibling,
+			Description: "Sibling headings have different texts",
+			Severity:    SeverityWarning,
+			Check:       checkDuplicateSiblings,
+		},
+		{
+			ID:          RuleEmptySection,
+			Description: "Every section has content or subsections",
+			Severity:    SeverityWarning,
+			Check:       checkEmptySections,
+		},
+		{
+			ID:          RuleTrailingPunctuation,
+			Description: "Headings do not end with punctuation",
+			Severity:    SeverityWarning,
+			Check:       checkTrailingPunctuation,
+			Fix:         fixTrailingPunctuation,
+		},
+		{
+			ID:          RuleTOCMarkers,
+			Description: "The document has a table of contents between gtoc markers",
+			Severity:    SeverityWarning,
+			Check:       checkTOCMarkers,
+			Fix:         insertTOC,
+		},
+	}
+}
+
+// checkHeadingIncrement reports headings more than one level deeper than
+// the heading before them.
+func checkHeadingIncrement(doc *Document) []Finding {
+	var findings []Finding
+	for i := 1; i < len(doc.Headings); i++ {
+		prev, h := doc.Headings[i-1], doc.Headings[i]
+		if h.Level > prev.Level+1 {
+			findings = append(findings, Finding{
+				Line:       h.Line,
+				Message:    fmt.Sprintf("heading level skips from h%d to h%d", prev.Level, h.Level),
+				Suggestion: fmt.Sprintf("make it an h%d heading (%s)", prev.Level+1, strings.Repeat("#", prev.Level+1)),
+			})
+		}
+	}
+	return findings
+}
+
+// checkSingleH1 reports every top-level heading after the first one.
+func checkSingleH1(doc *Document) []Finding {
+	var findings []Finding
+	first := 0
+	for _, h := range doc.Headings {
+		if h.Level != 1 {
+			continue
+		}
+		if first == 0 {
+			first = h.Line
+			continue
+		}
+		findings = append(findings, Finding{
+			Line:       h.Line,
+			Message:    fmt.Sprintf("multiple top-level headings; the first one is on line %d", first),
+			Suggestion: "make it an h2 heading (##) under the title",
+		})
+	}
+	return findings
+}
+
+// checkDuplicateSiblings reports headings with the same text, ignoring
+// case, as an earlier heading with the same parent.
+func checkDuplicateSiblings(doc *Document) []Finding {
+	var findings []Finding
+	seen := map[string]int{}
+	var parents []*generator.Heading
+	for _, h := range doc.Headings {
+		for len(parents) > 0 && parents[len(parents)-1].Level >= h.Level {
+			parents = parents[:len(parents)-1]
+		}
+		parent := 0
+		if len(parents) > 0 {
+			parent = parents[len(parents)-1].Line
+		}
+		parents = append(parents, h)
+
+		key := fmt.Sprintf("%d/%d/%s", parent, h.Level, strings.ToLower(h.Text))
+		if first, ok := seen[key]; ok {
+			findings = append(findings, Finding{
+				Line:       h.Line,
+				Message:    fmt.Sprintf("heading %q repeats its sibling on line %d", h.Text, first),
+				Suggestion: "rename one of the headings or merge the two sections",
+			})
+			continue
+		}
+		seen[key] = h.Line
+	}
+	return findings
+}
+
+// checkEmptySections reports headings followed by nothing but blank lines
+// before the next heading at the same or a shallower level.
+func checkEmptySections(doc *Document) []Finding {
+	var findings []Finding
+	for i, h := range doc.Headings {
+		end := len(doc.Lines) + 1
+		if i+1 < len(doc.Headings) {
+			next := doc.Headings[i+1]
+			if next.Level > h.Level {
+				continue
+			}
+			end = next.Line
+		}
+		if isBlank(doc.Lines[h.Line : end-1]) {
+			findings = append(findings, Finding{
+				Line:       h.Line,
+				Message:    fmt.Sprintf("section %q is empty", h.Text),
+				Suggestion: "add content under the heading or remove it",
+			})
+		}
+	}
+	return findings
+}
+
+// isBlank reports whether every line is empty or whitespace.
+func isBlank(lines []string) bool {
+	for _, line := range lines {
+		if strings.TrimSpace(line) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// checkTrailingPunctuation reports headings ending with punctuation.
+func checkTrailingPunctuation(doc *Document) []Finding {
+	var findings []Finding
+	for _, h := range doc.Headings {
+		if !hasTrailingPunctuation(h.Text) {
+			continue
+		}
+		trimmed := strings.TrimRight(h.Text, trailingPunctuation)
+		line := doc.Lines[h.Line-1]
+		findings = append(findings, Finding{
+			Line:       h.Line,
+			Column:     utf8.RuneCountInString(line[:strings.LastIndex(line, h.Text)+len(trimmed)]) + 1,
+			Message:    fmt.Sprintf("heading %q ends with punctuation", h.Text),
+			Suggestion: fmt.Sprintf("rename it to %q", trimmed),
+		})
+	}
+	return findings
+}
+
+// hasTrailingPunctuation reports whether text ends with a character of
+// trailingPunctuation that is not part of an HTML entity.
+func hasTrailingPunctuation(text string) bool {
+	trimmed := strings.TrimRight(text, trailingPunctuation)
+	return trimmed != text && !trailingEntityPattern.MatchString(text)
+}
+
+// fixTrailingPunctuation removes the trailing punctuation of every heading.
+// Punctuation never reaches an anchor, so links to the headings still work.
+func fixTrailingPunctuation(doc *Document) string {
+	lines := append([]string(nil), doc.Lines...)
+	for _, h := range doc.Headings {
+		if !hasTrailingPunctuation(h.Text) {
+			continue
+		}
+		line := lines[h.Line-1]
+		at := strings.LastIndex(line, h.Text)
+		lines[h.Line-1] = line[:at] + strings.TrimRight(h.Text, trailingPunctuation) + line[at+len(h.Text):]
+	}
+	return strings.Join(lines, "\n")
+}
+
+// checkTOCMarkers reports a document with headings but no table of
+// contents.
+func checkTOCMarkers(doc *Document) []Finding {
+	if len(doc.Headings) == 0 || generator.HasTOC(doc.Content) {
+		return nil
+	}
+	return []Finding{{
+		Line:       1,
+		Message:    "table of contents markers are missing",
+		Suggestion: "run gtoc generate on the file to add a table of contents",
+	}}
+}
+
+// insertTOC generates a table of contents and inserts it before the first
+// heading or, when the document has a single top-level heading that comes
+// first, right before the heading after it, below the title.
+func insertTOC(doc *Document) string {
+	at := doc.Headings[0].Line
+	if len(doc.Headings) > 1 && doc.Headings[0].Level == 1 && countLevel(doc.Headings, 1) == 1 {
+		at = doc.Headings[1].Line
+	}
+
+	before := strings.Join(doc.Lines[:at-1], "\n")
+	after := strings.Join(doc.Lines[at-1:], "\n")
+	if before != "" {
+		before += "\n"
+	}
+	return before + doc.toc.GenerateFromContent(doc.Content) + "\n\n" + after
+}
+
+// countLevel returns how many headings are at level.
+func countLevel(headings []*generator.Heading, level int) int {
+	n := 0
+	for _, h := range headings {
+		if h.Level == level {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,214 @@
+package lsp
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/lpsm-dev/gtoc/internal/generator"
+)
+
+// codeActionKind is the kind of the "Update table of contents" action.
+const codeActionKind = "source.updateTableOfContents"
+
+// tocStartMarker locates the TOC block for the stale-TOC diagnostic.
+const tocStartMarker = "<!-- START_TABLE_OF_CONTENTS -->"
+
+// Diagnostic codes reported by the server.
+const (
+	codeStaleTOC     = "stale-toc"
+	codeBrokenAnchor = "broken-anchor"
+)
+
+// documentSymbol returns the document's headings as a nested outline.
+func (s *Server) documentSymbol(params json.RawMessage) (any, error) {
+	var p documentParams
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, fmt.Errorf("%w: %v", errInvalidParams, err)
+	}
+
+	content := s.docs[p.TextDocument.URI]
+	lines := strings.Split(content, "\n")
+	headings := generator.NewGenerator("", 0, nil, generator.WithSlugger(s.opts.Slugger)).ParseHeadings(content)
+	return buildSymbols(headings, lines, len(lines)-1), nil
+}
+
+// buildSymbols nests headings by level. Each symbol's range spans from its
+// heading to the line before the next heading of the same or a higher level,
+// or to lastLine for the final heading.
+func buildSymbols(headings []*generator.Heading, lines []string, lastLine int) []DocumentSymbol {
+	symbols := []DocumentSymbol{}
+	for i := 0; i < len(headings); {
+		end := i + 1
+		for end < len(headings) && headings[end].Level > headings[i].Level {
+			end++
+		}
+
+		h := headings[i]
+		sectionEnd := lastLine
+		if end < len(headings) {
+			sectionEnd = headings[end].Line - 2
+		}
+		symbols = append(symbols, DocumentSymbol{
+			Name:           h.Text,
+			Detail:         "#" + h.Anchor,
+			Kind:           symbolKindString,
+			Range:          Range{Start: Position{Line: h.Line - 1}, End: lineEnd(lines, sectionEnd)},
+			SelectionRange: lineRange(lines, h.Line-1),
+			Children:       buildSymbols(headings[i+1:end], lines, sectionEnd),
+		})
+		i = end
+	}
+	return symbols
+}
+
+// codeAction offers to rewrite the document with an up-to-date TOC whenever
+// regenerating it would change the document.
+func (s *Server) codeAction(params json.RawMessage) (any, error) {
+	var p documentParams
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, fmt.Errorf("%w: %v", errInvalidParams, err)
+	}
+
+	uri := p.TextDocument.URI
+	content := s.docs[uri]
+	updated := s.updatedContent(uri, content)
+	if updated == content {
+		return []CodeAction{}, nil
+	}
+
+	lines := strings.Split(content, "\n")
+	edit := TextEdit{
+		Range:   Range{Start: Position{}, End: lineEnd(lines, len(lines)-1)},
+		NewText: updated,
+	}
+	return []CodeAction{{
+		Title: "Update table of contents",
+		Kind:  codeActionKind,
+		Edit:  &WorkspaceEdit{Changes: map[string][]TextEdit{uri: {edit}}},
+	}}, nil
+}
+
+// definition resolves an "#anchor" link under the cursor to the heading or
+// HTML anchor it points to.
+func (s *Server) definition(params json.RawMessage) (any, error) {
+	var p positionParams
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, fmt.Errorf("%w: %v", errInvalidParams, err)
+	}
+
+	content := s.docs[p.TextDocument.URI]
+	lines := strings.Split(content, "\n")
+	if p.Position.Line < 0 || p.Position.Line >= len(lines) {
+		return nil, nil
+	}
+	col := byteOffset(lines[p.Position.Line], p.Position.Character)
+
+	targets := s.anchorTargets(content)
+	for _, link := range generator.FindAnchorLinks(content) {
+		// The "#" just before Start is part of the clickable link too.
+		if link.Line-1 != p.Position.Line || col < link.Start-1 || col > link.End {
+			continue
+		}
+		if line, ok := targets[link.Anchor]; ok {
+			return Location{URI: p.TextDocument.URI, Range: lineRange(lines, line-1)}, nil
+		}
+	}
+	return nil, nil
+}
+
+// diagnostics reports a stale TOC block and links to anchors that no
+// heading or HTML anchor in the document defines.
+func (s *Server) diagnostics(uri, content string) []Diagnostic {
+	diags := []Diagnostic{}
+	lines := strings.Split(content, "\n")
+
+	if start := tocStartLine(lines); start >= 0 && s.updatedContent(uri, content) != content {
+		diags = append(diags, Diagnostic{
+			Range:    lineRange(lines, start),
+			Severity: severityWarning,
+			Code:     codeStaleTOC,
+			Source:   "gtoc",
+			Message:  "Table of contents is out of date",
+		})
+	}
+
+	targets := s.anchorTargets(content)
+	for _, link := range generator.FindAnchorLinks(content) {
+		if _, ok := targets[link.Anchor]; ok {
+			continue
+		}
+		line := lines[link.Line-1]
+		diags = append(diags, Diagnostic{
+			Range: Range{
+				Start: Position{Line: link.Line - 1, Character: utf16Len(line[:link.Start])},
+				End:   Position{Line: link.Line - 1, Character: utf16Len(line[:link.End])},
+			},
+			Severity: severityWarning,
+			Code:     codeBrokenAnchor,
+			Source:   "gtoc",
+			Message:  fmt.Sprintf("No heading or anchor named %q", link.Anchor),
+		})
+	}
+
+	return diags
+}
+
+// updatedContent returns content, the text of the document at uri, with its
+// TOC regenerated and, with NumberHeadings, its headings numbered.
+func (s *Server) updatedContent(uri, content string) string {
+	gen := s.generator(uri, content)
+	if s.opts.NumberHeadings {
+		return gen.GenerateNumberedContent(content)
+	}
+	return gen.GetFileWithUpdatedTOC(content, gen.GenerateFromContent(content))
+}
+
+// anchorTargets returns the anchors links in content can point to, slugged
+// with the server's slug style.
+func (s *Server) anchorTargets(content string) map[string]int {
+	return generator.NewGenerator("", 0, nil, generator.WithSlugger(s.opts.Slugger)).AnchorTargets(content)
+}
+
+// tocStartLine returns the zero-based line of the TOC start marker, or -1.
+func tocStartLine(lines []string) int {
+	for i, line := range lines {
+		if strings.Contains(line, tocStartMarker) {
+			return i
+		}
+	}
+	return -1
+}
+
+// lineRange returns the range covering the whole of line i.
+func lineRange(lines []string, i int) Range {
+	return Range{Start: Position{Line: i}, End: lineEnd(lines, i)}
+}
+
+// lineEnd returns the position just past the last character of line i.
+func lineEnd(lines []string, i int) Position {
+	if i < 0 || i >= len(lines) {
+		return Position{Line: max(i, 0)}
+	}
+	return Position{Line: i, Character: utf16Len(lines[i])}
+}
+
+// utf16Len returns the length of s in UTF-16 code units, the unit LSP
+// positions are measured in.
+func utf16Len(s string) int {
+	return len(utf16.Encode([]rune(s)))
+}
+
+// byteOffset converts a UTF-16 character offset within line into a byte
+// offset, clamping to the end of the line.
+func byteOffset(line string, character int) int {
+	units := 0
+	for i, r := range line {
+		if units >= character {
+			return i
+		}
+		units += utf16.RuneLen(r)
+	}
+	return len(line)
+}
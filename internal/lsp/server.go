@@ -0,0 +1,256 @@
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/textproto"
+	"strconv"
+	"strings"
+
+	"github.com/lpsm-dev/gtoc/internal/generator"
+	"github.com/lpsm-dev/gtoc/internal/i18n"
+	"github.com/lpsm-dev/gtoc/internal/logger"
+)
+
+// Options configures how the server generates tables of contents.
+type Options struct {
+	// Generator returns the Generator tables of contents are generated
+	// with, writing their text in messages; nil means one listing every
+	// heading.
+	Generator func(messages i18n.Messages) *generator.Generator
+	// NumberHeadings numbers the headings along with the table of contents,
+	// like gtoc generate --number-headings.
+	NumberHeadings bool
+	// Language forces the language of generated text. When empty it is
+	// detected per document, falling back to DefaultLanguage.
+	Language        string
+	DefaultLanguage string
+	// Catalog holds the localized strings; nil means the built-in catalog.
+	Catalog *i18n.Catalog
+	// Slugger turns headings into anchors; nil means GitHub's slug style.
+	Slugger generator.Slugger
+}
+
+// Server is a Language Server Protocol server speaking JSON-RPC over a pair
+// of streams, typically stdin and stdout.
+type Server struct {
+	in       *bufio.Reader
+	out      io.Writer
+	opts     Options
+	docs     map[string]string
+	shutdown bool
+}
+
+// handler processes the params of a request and returns its result.
+type handler func(s *Server, params json.RawMessage) (any, error)
+
+// errInvalidParams marks a handler failure caused by malformed params.
+var errInvalidParams = errors.New("invalid params")
+
+// requestHandlers maps the request methods the server answers to their
+// implementation.
+var requestHandlers = map[string]handler{
+	"initialize":                  (*Server).initialize,
+	"shutdown":                    (*Server).handleShutdown,
+	"textDocument/documentSymbol": (*Server).documentSymbol,
+	"textDocument/codeAction":     (*Server).codeAction,
+	"textDocument/definition":     (*Server).definition,
+}
+
+// NewServer creates a Server reading requests from in and writing responses
+// and notifications to out.
+func NewServer(in io.Reader, out io.Writer, opts Options) *Server {
+	if opts.Catalog == nil {
+		opts.Catalog = i18n.NewCatalog(nil)
+	}
+	if opts.Slugger == nil {
+		opts.Slugger, _ = generator.SluggerByName(generator.SlugGitHub)
+	}
+	if opts.Generator == nil {
+		slug := opts.Slugger
+		opts.Generator = func(messages i18n.Messages) *generator.Generator {
+			return generator.NewGenerator("", 0, nil, generator.WithMessages(messages), generator.WithSlugger(slug))
+		}
+	}
+	return &Server{
+		in:   bufio.NewReader(in),
+		out:  out,
+		opts: opts,
+		docs: map[string]string{},
+	}
+}
+
+// Run serves requests until the client sends "exit" or closes the input
+// stream. It returns an error when exit arrives without a prior shutdown,
+// as the specification requires a non-zero exit status in that case.
+func (s *Server) Run() error {
+	for {
+		body, err := s.readMessage()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		var req request
+		if err := json.Unmarshal(body, &req); err != nil {
+			s.reply(nil, nil, &responseError{Code: codeParseError, Message: err.Error()})
+			continue
+		}
+
+		if req.Method == "exit" {
+			if !s.shutdown {
+				return errors.New("exit received before shutdown")
+			}
+			return nil
+		}
+
+		s.dispatch(&req)
+	}
+}
+
+// dispatch routes a request to its handler, or a notification to the
+// matching document sync function. Unknown notifications are ignored.
+func (s *Server) dispatch(req *request) {
+	logger.Debug("LSP message", "method", req.Method)
+
+	if req.ID == nil {
+		s.notify(req.Method, req.Params)
+		return
+	}
+
+	h, ok := requestHandlers[req.Method]
+	if !ok {
+		s.reply(req.ID, nil, &responseError{Code: codeMethodNotFound, Message: "method not found: " + req.Method})
+		return
+	}
+
+	result, err := h(s, req.Params)
+	switch {
+	case errors.Is(err, errInvalidParams):
+		s.reply(req.ID, nil, &responseError{Code: codeInvalidParams, Message: err.Error()})
+	case err != nil:
+		s.reply(req.ID, nil, &responseError{Code: codeInternalError, Message: err.Error()})
+	default:
+		s.reply(req.ID, result, nil)
+	}
+}
+
+// notify handles the document synchronization notifications.
+func (s *Server) notify(method string, params json.RawMessage) {
+	switch method {
+	case "textDocument/didOpen":
+		var p didOpenParams
+		if json.Unmarshal(params, &p) == nil {
+			s.docs[p.TextDocument.URI] = p.TextDocument.Text
+			s.publishDiagnostics(p.TextDocument.URI)
+		}
+	case "textDocument/didChange":
+		var p didChangeParams
+		if json.Unmarshal(params, &p) == nil && len(p.ContentChanges) > 0 {
+			s.docs[p.TextDocument.URI] = p.ContentChanges[len(p.ContentChanges)-1].Text
+			s.publishDiagnostics(p.TextDocument.URI)
+		}
+	case "textDocument/didClose":
+		var p documentParams
+		if json.Unmarshal(params, &p) == nil {
+			delete(s.docs, p.TextDocument.URI)
+			s.send(notification{JSONRPC: "2.0", Method: "textDocument/publishDiagnostics",
+				Params: publishDiagnosticsParams{URI: p.TextDocument.URI, Diagnostics: []Diagnostic{}}})
+		}
+	}
+}
+
+// initialize advertises the server's capabilities.
+func (s *Server) initialize(params json.RawMessage) (any, error) {
+	return map[string]any{
+		"capabilities": map[string]any{
+			"textDocumentSync":       1, // Full document sync.
+			"documentSymbolProvider": true,
+			"definitionProvider":     true,
+			"codeActionProvider": map[string]any{
+				"codeActionKinds": []string{codeActionKind},
+			},
+		},
+		"serverInfo": map[string]string{"name": "gtoc"},
+	}, nil
+}
+
+// handleShutdown acknowledges shutdown; the process exits on "exit".
+func (s *Server) handleShutdown(params json.RawMessage) (any, error) {
+	s.shutdown = true
+	return nil, nil
+}
+
+// publishDiagnostics sends the current diagnostics of uri to the client.
+func (s *Server) publishDiagnostics(uri string) {
+	s.send(notification{
+		JSONRPC: "2.0",
+		Method:  "textDocument/publishDiagnostics",
+		Params:  publishDiagnosticsParams{URI: uri, Diagnostics: s.diagnostics(uri, s.docs[uri])},
+	})
+}
+
+// generator returns a Generator configured with the server's options and
+// the language of the document at uri.
+func (s *Server) generator(uri, content string) *generator.Generator {
+	lang := s.opts.Catalog.Resolve(s.opts.Language, s.opts.DefaultLanguage, uri, content)
+	return s.opts.Generator(s.opts.Catalog.Messages(lang))
+}
+
+// readMessage reads one Content-Length framed message body.
+func (s *Server) readMessage() ([]byte, error) {
+	header, err := textproto.NewReader(s.in).ReadMIMEHeader()
+	if err != nil {
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return nil, io.EOF
+		}
+		return nil, fmt.Errorf("failed to read message header: %w", err)
+	}
+
+	length, err := strconv.Atoi(strings.TrimSpace(header.Get("Content-Length")))
+	if err != nil || length < 0 {
+		return nil, fmt.Errorf("invalid Content-Length header %q", header.Get("Content-Length"))
+	}
+
+	body := make([]byte, length)
+	if _, err := io.ReadFull(s.in, body); err != nil {
+		return nil, fmt.Errorf("failed to read message body: %w", err)
+	}
+	return body, nil
+}
+
+// reply sends the response to request id.
+func (s *Server) reply(id json.RawMessage, result any, rpcErr *responseError) {
+	if id == nil {
+		id = json.RawMessage("null")
+	}
+	msg := response{JSONRPC: "2.0", ID: id, Error: rpcErr}
+	if rpcErr == nil {
+		body, err := json.Marshal(result)
+		if err != nil {
+			logger.Error("Failed to encode LSP result", "error", err)
+			msg.Error = &responseError{Code: codeInternalError, Message: err.Error()}
+		} else {
+			msg.Result = body
+		}
+	}
+	s.send(msg)
+}
+
+// send frames and writes msg. Write errors are logged rather than returned:
+// the next read will observe the closed stream and end Run.
+func (s *Server) send(msg any) {
+	body, err := json.Marshal(msg)
+	if err != nil {
+		logger.Error("Failed to encode LSP message", "error", err)
+		return
+	}
+	if _, err := fmt.Fprintf(s.out, "Content-Length: %d\r\n\r\n%s", len(body), body); err != nil {
+		logger.Error("Failed to write LSP message", "error", err)
+	}
+}
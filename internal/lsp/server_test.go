@@ -0,0 +1,249 @@
+package lsp
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/lpsm-dev/gtoc/internal/generator"
+	"github.com/lpsm-dev/gtoc/internal/i18n"
+)
+
+const testURI = "file:///tmp/README.md"
+
+const testDocument = "<!-- START_TABLE_OF_CONTENTS -->\n" +
+	"<!-- END_TABLE_OF_CONTENTS -->\n" +
+	"\n" +
+	"# Intro\n" +
+	"See [setup](#setup) and [gone](#missing).\n" +
+	"\n" +
+	"## Setup\n" +
+	"\n" +
+	"# Usage\n"
+
+// frame wraps each message in a Content-Length header, as a client would.
+func frame(t *testing.T, messages ...any) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	for _, m := range messages {
+		body, err := json.Marshal(m)
+		if err != nil {
+			t.Fatalf("failed to encode message: %v", err)
+		}
+		fmt.Fprintf(&buf, "Content-Length: %d\r\n\r\n%s", len(body), body)
+	}
+	return &buf
+}
+
+// readAll decodes every framed message the server wrote.
+func readAll(t *testing.T, out *bytes.Buffer) []map[string]json.RawMessage {
+	t.Helper()
+	s := NewServer(out, nil, Options{})
+	var msgs []map[string]json.RawMessage
+	for {
+		body, err := s.readMessage()
+		if err != nil {
+			return msgs
+		}
+		var m map[string]json.RawMessage
+		if err := json.Unmarshal(body, &m); err != nil {
+			t.Fatalf("server wrote invalid JSON: %v", err)
+		}
+		msgs = append(msgs, m)
+	}
+}
+
+// session runs the server over the given client messages, opening
+// testDocument first, and returns every message the server wrote.
+func session(t *testing.T, messages ...any) []map[string]json.RawMessage {
+	t.Helper()
+	open := map[string]any{
+		"jsonrpc": "2.0",
+		"method":  "textDocument/didOpen",
+		"params": map[string]any{
+			"textDocument": map[string]any{"uri": testURI, "languageId": "markdown", "version": 1, "text": testDocument},
+		},
+	}
+	in := frame(t, append([]any{open}, messages...)...)
+
+	var out bytes.Buffer
+	if err := NewServer(in, &out, Options{}).Run(); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	return readAll(t, &out)
+}
+
+// call builds a request for method with the given id and params.
+func call(id int, method string, params any) map[string]any {
+	return map[string]any{"jsonrpc": "2.0", "id": id, "method": method, "params": params}
+}
+
+// resultOf returns the result of the response with the given id.
+func resultOf(t *testing.T, msgs []map[string]json.RawMessage, id int) json.RawMessage {
+	t.Helper()
+	for _, m := range msgs {
+		if string(m["id"]) == fmt.Sprint(id) {
+			if m["error"] != nil {
+				t.Fatalf("request %d failed: %s", id, m["error"])
+			}
+			return m["result"]
+		}
+	}
+	t.Fatalf("no response to request %d", id)
+	return nil
+}
+
+func TestDocumentSymbols(t *testing.T) {
+	msgs := session(t, call(1, "textDocument/documentSymbol", map[string]any{"textDocument": map[string]string{"uri": testURI}}))
+
+	var symbols []DocumentSymbol
+	if err := json.Unmarshal(resultOf(t, msgs, 1), &symbols); err != nil {
+		t.Fatalf("invalid result: %v", err)
+	}
+
+	if len(symbols) != 2 || symbols[0].Name != "Intro" || symbols[1].Name != "Usage" {
+		t.Fatalf("expected top-level symbols Intro and Usage, got %+v", symbols)
+	}
+	if len(symbols[0].Children) != 1 || symbols[0].Children[0].Name != "Setup" {
+		t.Errorf("Setup should be nested under Intro, got %+v", symbols[0].Children)
+	}
+	if symbols[0].Range.End.Line != 7 {
+		t.Errorf("Intro should end on the line before Usage, got %+v", symbols[0].Range)
+	}
+}
+
+func TestDiagnostics(t *testing.T) {
+	msgs := session(t)
+
+	var params publishDiagnosticsParams
+	for _, m := range msgs {
+		if string(m["method"]) == `"textDocument/publishDiagnostics"` {
+			if err := json.Unmarshal(m["params"], &params); err != nil {
+				t.Fatalf("invalid diagnostics: %v", err)
+			}
+		}
+	}
+
+	codes := map[string]int{}
+	for _, d := range params.Diagnostics {
+		codes[d.Code]++
+	}
+	if codes[codeStaleTOC] != 1 {
+		t.Errorf("expected a stale TOC diagnostic, got %+v", params.Diagnostics)
+	}
+	if codes[codeBrokenAnchor] != 1 {
+		t.Errorf("expected exactly one broken anchor diagnostic (#missing), got %+v", params.Diagnostics)
+	}
+}
+
+func TestCodeActionUpdatesTOC(t *testing.T) {
+	msgs := session(t, call(1, "textDocument/codeAction", map[string]any{"textDocument": map[string]string{"uri": testURI}}))
+
+	var actions []CodeAction
+	if err := json.Unmarshal(resultOf(t, msgs, 1), &actions); err != nil {
+		t.Fatalf("invalid result: %v", err)
+	}
+	if len(actions) != 1 || actions[0].Edit == nil {
+		t.Fatalf("expected one code action with an edit, got %+v", actions)
+	}
+
+	edits := actions[0].Edit.Changes[testURI]
+	if len(edits) != 1 || !strings.Contains(edits[0].NewText, "[Setup](#setup)") {
+		t.Errorf("edit should contain the regenerated TOC, got %+v", edits)
+	}
+}
+
+func TestDefinition(t *testing.T) {
+	// Line 4 is "See [setup](#setup) ..."; character 14 is inside "#setup".
+	msgs := session(t, call(1, "textDocument/definition", map[string]any{
+		"textDocument": map[string]string{"uri": testURI},
+		"position":     Position{Line: 4, Character: 14},
+	}))
+
+	var loc Location
+	if err := json.Unmarshal(resultOf(t, msgs, 1), &loc); err != nil {
+		t.Fatalf("invalid result: %v", err)
+	}
+	if loc.Range.Start.Line != 6 {
+		t.Errorf("definition should point at the Setup heading (line 6), got %+v", loc)
+	}
+}
+
+func TestShutdownAndExit(t *testing.T) {
+	in := frame(t,
+		call(1, "shutdown", nil),
+		map[string]any{"jsonrpc": "2.0", "method": "exit"},
+		call(2, "initialize", map[string]any{}),
+	)
+
+	var out bytes.Buffer
+	if err := NewServer(in, &out, Options{}).Run(); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if strings.Contains(out.String(), `"id":2`) {
+		t.Error("the server should stop reading after exit")
+	}
+	if !strings.Contains(out.String(), `"result":null`) {
+		t.Errorf("a successful shutdown reply must carry a null result, got %s", out.String())
+	}
+
+	in = frame(t, map[string]any{"jsonrpc": "2.0", "method": "exit"})
+	if err := NewServer(in, &bytes.Buffer{}, Options{}).Run(); err == nil {
+		t.Error("exit without shutdown should return an error")
+	}
+}
+
+func TestUnknownMethod(t *testing.T) {
+	msgs := session(t, call(1, "textDocument/hover", map[string]any{}))
+	for _, m := range msgs {
+		if string(m["id"]) == "1" {
+			if !strings.Contains(string(m["error"]), fmt.Sprint(codeMethodNotFound)) {
+				t.Errorf("expected method not found error, got %s", m["error"])
+			}
+			if _, ok := m["result"]; ok {
+				t.Errorf("an error response must not carry a result, got %s", m["result"])
+			}
+			return
+		}
+	}
+	t.Fatal("no response to unknown method")
+}
+
+func TestUTF16Offsets(t *testing.T) {
+	line := "## Instalação 😀 [x](#y)"
+	if got := utf16Len(line); got != len([]rune(line))+1 {
+		t.Errorf("utf16Len(%q) = %d, want %d", line, got, len([]rune(line))+1)
+	}
+	if got := byteOffset("é😀a", 3); got != len("é😀") {
+		t.Errorf("byteOffset should skip the surrogate pair, got %d", got)
+	}
+}
+
+func TestUpdatedContentFollowsDocumentLanguage(t *testing.T) {
+	s := NewServer(&bytes.Buffer{}, &bytes.Buffer{}, Options{})
+	updated := s.updatedContent("file:///tmp/README.pt-BR.md", testDocument)
+	if !strings.Contains(updated, ">voltar ao topo</a>") {
+		t.Errorf("TOC of a pt-BR document should use the Portuguese back-to-top text, got:\n%s", updated)
+	}
+	if diags := s.diagnostics("file:///tmp/README.pt-BR.md", updated); len(diags) != 0 && diags[0].Code == codeStaleTOC {
+		t.Errorf("a freshly generated localized TOC should not be reported as stale, got %+v", diags)
+	}
+}
+
+func TestUpdatedContentUsesGeneratorOptions(t *testing.T) {
+	s := NewServer(&bytes.Buffer{}, &bytes.Buffer{}, Options{
+		Generator: func(messages i18n.Messages) *generator.Generator {
+			return generator.NewGenerator("", 1, nil, generator.WithMessages(messages))
+		},
+		NumberHeadings: true,
+	})
+	updated := s.updatedContent(testURI, testDocument)
+	if !strings.Contains(updated, "# 1. Intro\n") || strings.Contains(updated, "(#setup)<br>") {
+		t.Errorf("the TOC should follow the generator's depth and number the headings, got:\n%s", updated)
+	}
+	if diags := s.diagnostics(testURI, updated); len(diags) != 0 && diags[0].Code == codeStaleTOC {
+		t.Errorf("a TOC generated with the server's options should not be reported as stale, got %+v", diags)
+	}
+}
@@ -0,0 +1,71 @@
+package scaffold
+
+import (
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// modulePattern matches the module directive of a go.mod file.
+var modulePattern = regexp.MustCompile(`(?m)^module\s+"?([^"\s]+)"?`)
+
+// majorVersionPattern matches the major version suffix of a module path,
+// such as "v2".
+var majorVersionPattern = regexp.MustCompile(`^v[0-9]+$`)
+
+// DetectProject returns the name and description of the project in dir. The
+// name comes from the module path in go.mod, or else the directory name;
+// the description is the first paragraph of the doc comment of the Go
+// package in dir, or "" when there is none.
+func DetectProject(dir string) (name, description string) {
+	name = moduleName(dir)
+	if name == "" {
+		if abs, err := filepath.Abs(dir); err == nil {
+			name = filepath.Base(abs)
+		}
+	}
+	return name, packageSynopsis(dir)
+}
+
+// moduleName returns the last element of the module path declared in
+// dir/go.mod, skipping a major version suffix, or "" without one.
+func moduleName(dir string) string {
+	content, err := os.ReadFile(filepath.Join(dir, "go.mod"))
+	if err != nil {
+		return ""
+	}
+	m := modulePattern.FindSubmatch(content)
+	if m == nil {
+		return ""
+	}
+	elems := strings.Split(string(m[1]), "/")
+	if n := len(elems); n > 1 && majorVersionPattern.MatchString(elems[n-1]) {
+		return elems[n-2]
+	}
+	return elems[len(elems)-1]
+}
+
+// packageSynopsis returns the first paragraph of the first package doc
+// comment found in the non-test Go files of dir, on a single line.
+func packageSynopsis(dir string) string {
+	paths, _ := filepath.Glob(filepath.Join(dir, "*.go"))
+	sort.Strings(paths)
+
+	fset := token.NewFileSet()
+	for _, path := range paths {
+		if strings.HasSuffix(path, "_test.go") {
+			continue
+		}
+		f, err := parser.ParseFile(fset, path, nil, parser.PackageClauseOnly|parser.ParseComments)
+		if err != nil || f.Doc == nil {
+			continue
+		}
+		paragraph, _, _ := strings.Cut(strings.TrimSpace(f.Doc.Text()), "\n\n")
+		return strings.Join(strings.Fields(paragraph), " ")
+	}
+	return ""
+}
@@ -0,0 +1,176 @@
+// Package scaffold renders README skeletons from built-in or user templates
+// and detects the project they describe.
+package scaffold
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/lpsm-dev/gtoc/internal/i18n"
+)
+
+// DefaultTemplate is the built-in template used when none is given.
+const DefaultTemplate = "standard"
+
+// Data is what a template renders.
+type Data struct {
+	// Name and Description describe the project; Description may be empty.
+	Name        string
+	Description string
+	// Lang is the language of the README and Messages its localized
+	// strings.
+	Lang     string
+	Messages i18n.Messages
+}
+
+// builtin holds the built-in templates, keyed by name. The section function
+// returns a standard section title in the README's language.
+var builtin = map[string]string{
+	"standard": `# {{.Name}}
+
+{{with .Description}}{{.}}{{else}}TODO{{end}}
+
+**{{.Messages.TableOfContents}}**
+
+<!-- START_TABLE_OF_CONTENTS -->
+<!-- END_TABLE_OF_CONTENTS -->
+
+## {{section "installation"}}
+
+TODO
+
+## {{section "usage"}}
+
+TODO
+
+## {{section "configuration"}}
+
+TODO
+
+## {{section "contributing"}}
+
+TODO
+
+## {{section "license"}}
+
+TODO
+`,
+	"minimal": `# {{.Name}}
+
+{{with .Description}}{{.}}{{else}}TODO{{end}}
+
+**{{.Messages.TableOfContents}}**
+
+<!-- START_TABLE_OF_CONTENTS -->
+<!-- END_TABLE_OF_CONTENTS -->
+
+## {{section "usage"}}
+
+TODO
+
+## {{section "license"}}
+
+TODO
+`,
+}
+
+// sectionTitles holds the standard section titles, keyed by section and
+// then by language tag.
+var sectionTitles = map[string]map[string]string{
+	"installation": {
+		"en": "Installation", "pt-BR": "Instalação", "es": "Instalación",
+		"fr": "Installation", "de": "Installation", "it": "Installazione",
+	},
+	"usage": {
+		"en": "Usage", "pt-BR": "Uso", "es": "Uso",
+		"fr": "Utilisation", "de": "Verwendung", "it": "Utilizzo",
+	},
+	"configuration": {
+		"en": "Configuration", "pt-BR": "Configuração", "es": "Configuración",
+		"fr": "Configuration", "de": "Konfiguration", "it": "Configurazione",
+	},
+	"contributing": {
+		"en": "Contributing", "pt-BR": "Contribuindo", "es": "Contribuir",
+		"fr": "Contribuer", "de": "Mitwirken", "it": "Contribuire",
+	},
+	"license": {
+		"en": "License", "pt-BR": "Licença", "es": "Licencia",
+		"fr": "Licence", "de": "Lizenz", "it": "Licenza",
+	},
+}
+
+// Templates returns the names of the built-in templates.
+func Templates() []string {
+	names := make([]string, 0, len(builtin))
+	for name := range builtin {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Load returns the built-in template called name or, when there is none,
+// the content of the template file at path name.
+func Load(name string) (string, error) {
+	if tmpl, ok := builtin[name]; ok {
+		return tmpl, nil
+	}
+	content, err := os.ReadFile(name)
+	if errors.Is(err, os.ErrNotExist) {
+		return "", fmt.Errorf("unknown template %q (built-in: %s, or a template file)", name, strings.Join(Templates(), ", "))
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read template: %w", err)
+	}
+	return string(content), nil
+}
+
+// Render executes the text/template tmpl with data.
+func Render(tmpl string, data Data) (string, error) {
+	t, err := template.New("readme").
+		Funcs(template.FuncMap{"section": func(key string) (string, error) {
+			return SectionTitle(key, data.Lang)
+		}}).
+		Parse(tmpl)
+	if err != nil {
+		return "", fmt.Errorf("invalid template: %w", err)
+	}
+
+	var sb strings.Builder
+	if err := t.Execute(&sb, data); err != nil {
+		return "", fmt.Errorf("failed to render template: %w", err)
+	}
+	return sb.String(), nil
+}
+
+// SectionTitle returns the title of the standard section key in lang,
+// falling back to a language with the same primary subtag and then to
+// English.
+func SectionTitle(key, lang string) (string, error) {
+	titles, ok := sectionTitles[key]
+	if !ok {
+		return "", fmt.Errorf("unknown section %q", key)
+	}
+	for tag, title := range titles {
+		if strings.EqualFold(tag, lang) {
+			return title, nil
+		}
+	}
+	for tag, title := range titles {
+		if strings.EqualFold(primarySubtag(tag), primarySubtag(lang)) {
+			return title, nil
+		}
+	}
+	return titles[i18n.DefaultLanguage], nil
+}
+
+// primarySubtag returns the language subtag of a tag such as "pt-BR" or
+// "pt_BR".
+func primarySubtag(tag string) string {
+	subtag, _, _ := strings.Cut(strings.ReplaceAll(tag, "_", "-"), "-")
+	return subtag
+}
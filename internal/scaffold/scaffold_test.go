@@ -0,0 +1,97 @@
+package scaffold
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/lpsm-dev/gtoc/internal/i18n"
+)
+
+func TestRenderBuiltinTemplates(t *testing.T) {
+	catalog := i18n.NewCatalog(nil)
+	for _, name := range Templates() {
+		tmpl, err := Load(name)
+		if err != nil {
+			t.Fatalf("Load(%q) failed: %v", name, err)
+		}
+		got, err := Render(tmpl, Data{Name: "widget", Lang: "pt-BR", Messages: catalog.Messages("pt-BR")})
+		if err != nil {
+			t.Fatalf("Render(%q) failed: %v", name, err)
+		}
+		for _, want := range []string{"# widget\n", "## Uso\n", "## Licença\n", "**Sumário**", "<!-- START_TABLE_OF_CONTENTS -->"} {
+			if !strings.Contains(got, want) {
+				t.Errorf("template %q: missing %q in:\n%s", name, want, got)
+			}
+		}
+	}
+}
+
+func TestRenderUserTemplate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "service.md")
+	if err := os.WriteFile(path, []byte("# {{.Name}}\n\n{{.Description}}\n\n## {{section \"usage\"}}\n"), 0644); err != nil {
+		t.Fatalf("failed to write template: %v", err)
+	}
+	tmpl, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	got, err := Render(tmpl, Data{Name: "api", Description: "The API.", Lang: "es-MX"})
+	if err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	if want := "# api\n\nThe API.\n\n## Uso\n"; got != want {
+		t.Errorf("Render = %q, want %q", got, want)
+	}
+
+	if _, err := Render(`{{section "faq"}}`, Data{}); err == nil {
+		t.Error("Render should fail on an unknown section")
+	}
+	if _, err := Load(filepath.Join(t.TempDir(), "missing.md")); err == nil {
+		t.Error("Load should fail on a missing template")
+	}
+}
+
+func TestSectionTitle(t *testing.T) {
+	tests := []struct {
+		key, lang, want string
+	}{
+		{"installation", "en", "Installation"},
+		{"installation", "pt-BR", "Instalação"},
+		{"installation", "pt_br", "Instalação"},
+		{"usage", "fr-CA", "Utilisation"},
+		{"license", "ja", "License"},
+		{"license", "", "License"},
+	}
+	for _, tt := range tests {
+		if got, err := SectionTitle(tt.key, tt.lang); err != nil || got != tt.want {
+			t.Errorf("SectionTitle(%q, %q) = %q, %v, want %q", tt.key, tt.lang, got, err, tt.want)
+		}
+	}
+}
+
+func TestDetectProject(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "checkout")
+	if err := os.Mkdir(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if name, description := DetectProject(dir); name != "checkout" || description != "" {
+		t.Errorf("DetectProject without go.mod = %q, %q", name, description)
+	}
+
+	files := map[string]string{
+		"go.mod":      "module github.com/acme/widget/v2\n\ngo 1.25\n",
+		"doc.go":      "// Package widget renders widgets for the\n// terminal.\n//\n// Details.\npackage widget\n",
+		"doc_test.go": "// Package widget_test is not the synopsis.\npackage widget_test\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	name, description := DetectProject(dir)
+	if name != "widget" || description != "Package widget renders widgets for the terminal." {
+		t.Errorf("DetectProject = %q, %q", name, description)
+	}
+}
@@ -0,0 +1,104 @@
+// Package score grades a README from 0 to 100 out of weighted categories,
+// each scored by the share of its checks that pass.
+package score
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+)
+
+// Category is one scored aspect of a README, such as its heading structure.
+// Passed of Total checks pass; a category with nothing to check scores its
+// full weight. A skipped category does not count towards the score.
+type Category struct {
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	Weight  int    `json:"weight"`
+	Passed  int    `json:"passed"`
+	Total   int    `json:"total"`
+	Skipped bool   `json:"skipped,omitempty"`
+	// Detail explains the result, such as what is missing.
+	Detail string `json:"detail"`
+}
+
+// Points returns the share of its weight the category earns.
+func (c Category) Points() float64 {
+	if c.Total == 0 {
+		return float64(c.Weight)
+	}
+	return float64(c.Weight) * float64(c.Passed) / float64(c.Total)
+}
+
+// Report is the score card of a README.
+type Report struct {
+	Path       string     `json:"file"`
+	Score      int        `json:"score"`
+	Grade      string     `json:"grade"`
+	Categories []Category `json:"categories"`
+}
+
+// New returns the report for the README at path: the points of the
+// categories that are not skipped, scaled to 0-100.
+func New(path string, categories []Category) Report {
+	points, weight := 0.0, 0
+	for _, c := range categories {
+		if c.Skipped {
+			continue
+		}
+		points += c.Points()
+		weight += c.Weight
+	}
+
+	score := 100
+	if weight > 0 {
+		score = int(math.Round(points * 100 / float64(weight)))
+	}
+	return Report{Path: path, Score: score, Grade: Grade(score), Categories: categories}
+}
+
+// Grade returns the letter grade of a score: A from 90, B from 80, C from
+// 70, D from 60 and F below.
+func Grade(score int) string {
+	switch {
+	case score >= 90:
+		return "A"
+	case score >= 80:
+		return "B"
+	case score >= 70:
+		return "C"
+	case score >= 60:
+		return "D"
+	default:
+		return "F"
+	}
+}
+
+// WriteText writes the report as a score line followed by one line per
+// category with its points, checks and detail.
+func WriteText(w io.Writer, r Report) error {
+	if _, err := fmt.Fprintf(w, "%s: %d/100 (%s)\n\n", r.Path, r.Score, r.Grade); err != nil {
+		return err
+	}
+	for _, c := range r.Categories {
+		var err error
+		if c.Skipped {
+			_, err = fmt.Fprintf(w, "  %-20s %7s  %s\n", c.Name, "skipped", c.Detail)
+		} else {
+			points := fmt.Sprintf("%d/%d", int(math.Round(c.Points())), c.Weight)
+			_, err = fmt.Fprintf(w, "  %-20s %7s  %s\n", c.Name, points, c.Detail)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteJSON writes the report as a JSON object.
+func WriteJSON(w io.Writer, r Report) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
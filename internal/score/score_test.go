@@ -0,0 +1,92 @@
+package score
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name       string
+		categories []Category
+		want       int
+	}{
+		{
+			name: "partial credit",
+			categories: []Category{
+				{ID: "a", Weight: 50, Passed: 1, Total: 2},
+				{ID: "b", Weight: 50, Passed: 3, Total: 3},
+			},
+			want: 75,
+		},
+		{
+			name: "nothing to check earns the full weight",
+			categories: []Category{
+				{ID: "a", Weight: 60, Passed: 0, Total: 1},
+				{ID: "b", Weight: 40},
+			},
+			want: 40,
+		},
+		{
+			name: "skipped categories are left out",
+			categories: []Category{
+				{ID: "a", Weight: 20, Passed: 1, Total: 1},
+				{ID: "b", Weight: 80, Skipped: true},
+			},
+			want: 100,
+		},
+		{
+			name: "rounds to the nearest point",
+			categories: []Category{
+				{ID: "a", Weight: 100, Passed: 2, Total: 3},
+			},
+			want: 67,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := New("README.md", tt.categories).Score; got != tt.want {
+				t.Errorf("Score = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGrade(t *testing.T) {
+	for score, want := range map[int]string{100: "A", 90: "A", 89: "B", 80: "B", 75: "C", 60: "D", 59: "F", 0: "F"} {
+		if got := Grade(score); got != want {
+			t.Errorf("Grade(%d) = %q, want %q", score, got, want)
+		}
+	}
+}
+
+func TestWriteReport(t *testing.T) {
+	report := New("README.md", []Category{
+		{ID: "toc", Name: "Table of contents", Weight: 15, Passed: 1, Total: 2, Detail: "out of date"},
+		{ID: "sections", Name: "Required sections", Weight: 15, Skipped: true, Detail: "no section policy"},
+	})
+
+	var text bytes.Buffer
+	if err := WriteText(&text, report); err != nil {
+		t.Fatalf("WriteText failed: %v", err)
+	}
+	for _, want := range []string{"README.md: 50/100 (F)\n", "Table of contents       8/15  out of date\n", "Required sections    skipped  no section policy\n"} {
+		if !strings.Contains(text.String(), want) {
+			t.Errorf("text report missing %q:\n%s", want, text.String())
+		}
+	}
+
+	var out bytes.Buffer
+	if err := WriteJSON(&out, report); err != nil {
+		t.Fatalf("WriteJSON failed: %v", err)
+	}
+	var decoded Report
+	if err := json.Unmarshal(out.Bytes(), &decoded); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out.String())
+	}
+	if decoded.Score != 50 || decoded.Grade != "F" || len(decoded.Categories) != 2 || !decoded.Categories[1].Skipped {
+		t.Errorf("decoded report = %+v", decoded)
+	}
+}